@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 	"wordle-tournament-backend/internal/config"
 	"wordle-tournament-backend/internal/server"
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle/corpus"
 )
 
 func main() {
@@ -12,10 +16,50 @@ func main() {
 	log.Printf("Starting Wordle Tournament API...")
 	log.Printf("Port: %s", cfg.Port)
 
-	srv := server.New()
+	if cfg.MaxGuesses > storage.MaxGuessesPerGame {
+		log.Fatalf("MAX_GUESSES must be at most %d, got %d", storage.MaxGuessesPerGame, cfg.MaxGuesses)
+	}
+
+	if err := corpus.LoadEmbedded(); err != nil {
+		log.Fatalf("Failed to load corpora: %v", err)
+	}
+	if cfg.CorpusDir != "" {
+		if err := corpus.LoadDir(cfg.CorpusDir); err != nil {
+			log.Fatalf("Failed to load corpora: %v", err)
+		}
+	}
+	log.Printf("Corpora: %v", corpus.Names())
+
+	store, err := newStore(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+	defer cancel()
+	if err := store.Ready(ctx); err != nil {
+		log.Fatalf("Storage is not ready: %v", err)
+	}
+
+	srv := server.New(store)
 
 	log.Printf("Server listening on :%s", cfg.Port)
 	if err := srv.Start(cfg.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// readinessTimeout bounds the storage readiness check made at startup.
+const readinessTimeout = 10 * time.Second
+
+// newStore returns a DynamoDB-backed store when DYNAMODB_ENDPOINT is set,
+// and an in-memory store otherwise so the API can run without Docker.
+func newStore(cfg config.Config) (storage.Store, error) {
+	if cfg.DynamoDBEndpoint == "" {
+		log.Printf("DYNAMODB_ENDPOINT not set, using in-memory storage")
+		return storage.NewMemoryStore(), nil
+	}
+
+	log.Printf("DynamoDB endpoint: %s", cfg.DynamoDBEndpoint)
+	return storage.NewDynamoStore()
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// apiKeyBytes is the number of random bytes in a generated API key.
+const apiKeyBytes = 32
+
+// GenerateAPIKey returns a new random API key, hex encoded.
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate API key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of key. Only hashes are
+// stored, so a leaked Teams table does not leak usable keys.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header. Returns an empty string if the header is missing or malformed.
+func BearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
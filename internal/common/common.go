@@ -1,6 +1,7 @@
 package common
 
-// WordLength is the number of letters in a valid Wordle word
-const WordLength = 5
 const DummyGuess = "imagine guessing more than 5 letters"
+
+// NumTargetWords is the number of games in a full run of the default corpus,
+// one per possible answer. Runs can be configured to play fewer.
 const NumTargetWords = 2315
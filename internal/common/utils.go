@@ -1,23 +1,30 @@
 package common
 
 import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"log"
 	"strconv"
-	"time"
 
 	"wordle-tournament-backend/internal/config"
 )
 
-// GetSeed returns the random seed to use for game generation.
-// If RANDOM_SEED environment variable is set, uses that value.
-// Otherwise, uses the current time in nanoseconds for randomness.
-func GetSeed() int64 {
-	cfg := config.Get()
-	if cfg.RandomSeed != "" {
-		seed, err := strconv.ParseInt(cfg.RandomSeed, 10, 64)
-		if err != nil {
-			return time.Now().UnixNano()
+// GetSeed returns the random seed to use for game generation. Seeds come from
+// crypto/rand, since the answers of a run can be regenerated from its seed.
+// RANDOM_SEED overrides it with a fixed seed, which is only meant for tests.
+func GetSeed() (int64, error) {
+	if value := config.Get().RandomSeed; value != "" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return seed, nil
 		}
-		return seed
+		log.Printf("Ignoring invalid RANDOM_SEED=%q", value)
 	}
-	return time.Now().UnixNano()
+
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("generate seed: %w", err)
+	}
+	return int64(binary.LittleEndian.Uint64(b[:])), nil
 }
@@ -1,15 +1,33 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type Config struct {
 	Port             string
 	Region           string
 	DynamoDBEndpoint string
-	RandomSeed       string
+	MaxGuesses       int
+	AdminAPIKey      string
+	RequestTimeout   time.Duration
+
+	// RandomSeed fixes the seed of every run that does not pick its own. It
+	// makes answers predictable and is only meant for tests.
+	RandomSeed string
+
+	// CorpusDir is a directory of extra corpora to load at startup, one
+	// subdirectory per corpus. Empty loads only the embedded corpora.
+	CorpusDir string
+
+	// SkipInvalidGuesses makes a guess submission with invalid words play its
+	// valid guesses and leave the invalid slots untouched, instead of
+	// rejecting the whole submission.
+	SkipInvalidGuesses bool
 }
 
 var (
@@ -26,10 +44,15 @@ func Get() Config {
 
 func initializeConfig() {
 	cfg = Config{
-		Port:             getEnv("PORT", "8080"),
-		Region:           getEnv("AWS_REGION", "us-east-1"),
-		DynamoDBEndpoint: getEnv("DYNAMODB_ENDPOINT", ""),
-		RandomSeed:       getEnv("RANDOM_SEED", ""),
+		Port:               getEnv("PORT", "8080"),
+		Region:             getEnv("AWS_REGION", "us-east-1"),
+		DynamoDBEndpoint:   getEnv("DYNAMODB_ENDPOINT", ""),
+		RandomSeed:         getEnv("RANDOM_SEED", ""),
+		MaxGuesses:         getEnvInt("MAX_GUESSES", 6),
+		AdminAPIKey:        getEnv("ADMIN_API_KEY", ""),
+		RequestTimeout:     getEnvDuration("REQUEST_TIMEOUT", 5*time.Second),
+		CorpusDir:          getEnv("CORPUS_DIR", ""),
+		SkipInvalidGuesses: getEnv("INVALID_GUESSES", "reject") == "skip",
 	}
 }
 
@@ -39,3 +62,35 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt returns the integer value of the environment variable key, or
+// defaultValue if it is unset or not a positive integer.
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Ignoring invalid %s=%q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvDuration returns the environment variable key parsed as a duration
+// such as "5s", or defaultValue if it is unset or not positive.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Ignoring invalid %s=%q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
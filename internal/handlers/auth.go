@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"wordle-tournament-backend/internal/auth"
+	"wordle-tournament-backend/internal/config"
+	"wordle-tournament-backend/internal/storage"
+)
+
+type teamContextKey struct{}
+
+// RequireTeam authenticates the request's bearer API key against the team
+// registry and passes the owning team_id to next through the request context.
+// Requests without a valid key are rejected with 401.
+func RequireTeam(teams storage.TeamStore, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey := auth.BearerToken(r)
+		if apiKey == "" {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "missing API key: set the Authorization header to 'Bearer <api_key>'", nil)
+			return
+		}
+
+		team, err := teams.GetTeamByAPIKeyHash(r.Context(), auth.HashAPIKey(apiKey))
+		if err != nil {
+			writeStorageError(w, err)
+			return
+		}
+		if team == nil {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "invalid API key", nil)
+			return
+		}
+
+		next(w, r.WithContext(withTeamID(r.Context(), team.TeamID)))
+	}
+}
+
+// RequireAdmin only lets requests through whose bearer token matches
+// ADMIN_API_KEY. Admin endpoints are disabled when ADMIN_API_KEY is not set.
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminKey := config.Get().AdminAPIKey
+		if adminKey == "" {
+			writeError(w, http.StatusForbidden, CodeForbidden, "admin endpoints are disabled: ADMIN_API_KEY is not set", nil)
+			return
+		}
+
+		token := auth.BearerToken(r)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminKey)) != 1 {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "invalid admin API key", nil)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func withTeamID(ctx context.Context, teamID string) context.Context {
+	return context.WithValue(ctx, teamContextKey{}, teamID)
+}
+
+// authenticatedTeamID returns the team_id set by RequireTeam.
+func authenticatedTeamID(r *http.Request) string {
+	teamID, _ := r.Context().Value(teamContextKey{}).(string)
+	return teamID
+}
+
+// resolveTeamID returns the authenticated team_id. A team_id supplied in the
+// request body is optional, but if present it must match the API key's team.
+func resolveTeamID(w http.ResponseWriter, r *http.Request, requested string) (string, bool) {
+	teamID := authenticatedTeamID(r)
+	if requested != "" && requested != teamID {
+		writeError(w, http.StatusForbidden, CodeForbidden, "team_id does not match the API key", nil)
+		return "", false
+	}
+	return teamID, true
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wordle-tournament-backend/internal/storage"
+)
+
+func registerTeam(t *testing.T, teams storage.TeamStore, teamID string) string {
+	t.Helper()
+	rec := postJSON(t, RegisterTeamHandler(teams), "/admin/teams", RegisterTeamRequest{TeamID: teamID})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 from /admin/teams, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp RegisterTeamResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.APIKey == "" {
+		t.Fatal("registration should return an API key")
+	}
+	return resp.APIKey
+}
+
+func TestRegisterTeamRejectsDuplicates(t *testing.T) {
+	teams := storage.NewMemoryTeamStore()
+	registerTeam(t, teams, "team")
+
+	rec := postJSON(t, RegisterTeamHandler(teams), "/admin/teams", RegisterTeamRequest{TeamID: "team"})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate team, got %d", rec.Code)
+	}
+}
+
+func TestRequireTeam(t *testing.T) {
+	teams := storage.NewMemoryTeamStore()
+	apiKey := registerTeam(t, teams, "team")
+
+	var gotTeamID string
+	handler := RequireTeam(teams, func(w http.ResponseWriter, r *http.Request) {
+		gotTeamID = authenticatedTeamID(r)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + apiKey, http.StatusUnauthorized},
+		{"unknown key", "Bearer not-a-key", http.StatusUnauthorized},
+		{"valid key", "Bearer " + apiKey, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTeamID = ""
+			req := httptest.NewRequest(http.MethodPost, "/start", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, rec.Code)
+			}
+			if tt.status == http.StatusOK && gotTeamID != "team" {
+				t.Errorf("expected authenticated team_id %q, got %q", "team", gotTeamID)
+			}
+		})
+	}
+}
+
+func TestStartRejectsSpoofedTeamID(t *testing.T) {
+	store := storage.NewMemoryStore()
+
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{TeamID: "victim"})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 when team_id does not match the API key, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle"
+)
+
+// Error codes sent in ErrorResponse. They are part of the API: clients match
+// on them, so existing codes must not change.
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeInvalidTeamID        = "invalid_team_id"
+	CodeInvalidGuessCount    = "invalid_guess_count"
+	CodeInvalidWordLength    = "invalid_word_length"
+	CodeWordNotInCorpus      = "word_not_in_corpus"
+	CodeInvalidGameIndex     = "invalid_game_index"
+	CodeHardModeViolation    = "hard_mode_violation"
+	CodeInvalidGuesses       = "invalid_guesses"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeRunNotFound          = "run_not_found"
+	CodeRunExpired           = "run_expired"
+	CodeRunQuotaExceeded     = "run_quota_exceeded"
+	CodeTournamentNotFound   = "tournament_not_found"
+	CodeTournamentClosed     = "tournament_closed"
+	CodeTournamentExists     = "tournament_exists"
+	CodeScoreNotFound        = "score_not_found"
+	CodeConflict             = "conflict"
+	CodeTeamExists           = "team_exists"
+	CodeTimeout              = "timeout"
+	CodeUnavailable          = "unavailable"
+	CodeInternal             = "internal_error"
+)
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an error. Code is stable and meant for programs; Message
+// is meant for people and may change. Details holds extra fields for some
+// codes, such as the game index of an invalid guess.
+type ErrorBody struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// writeError sends an ErrorResponse with the given status code.
+func writeError(w http.ResponseWriter, statusCode int, code, message string, details map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: ErrorBody{Code: code, Message: message, Details: details}})
+}
+
+// writeMethodNotAllowed rejects a request whose method the handler does not serve.
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "HTTP Method not allowed", nil)
+}
+
+// writeStorageError reports a failed storage call. A call cut off by the
+// request deadline reports 504, and one abandoned because the client went
+// away or the database could not be reached reports 503.
+func writeStorageError(w http.ResponseWriter, err error) {
+	statusCode, code := storageErrorStatus(err)
+	writeError(w, statusCode, code, err.Error(), nil)
+}
+
+// storageErrorStatus picks the status code and error code for a failed storage call.
+func storageErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, storage.ErrRunNotFound):
+		return http.StatusNotFound, CodeRunNotFound
+	case errors.Is(err, storage.ErrRunExpired):
+		return http.StatusGone, CodeRunExpired
+	case errors.Is(err, storage.ErrConflict):
+		return http.StatusConflict, CodeConflict
+	case errors.Is(err, storage.ErrTeamExists):
+		return http.StatusConflict, CodeTeamExists
+	case errors.Is(err, storage.ErrTournamentExists):
+		return http.StatusConflict, CodeTournamentExists
+	case errors.Is(err, storage.ErrRunQuotaExceeded):
+		return http.StatusForbidden, CodeRunQuotaExceeded
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, CodeTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, storage.ErrUnavailable):
+		return http.StatusServiceUnavailable, CodeUnavailable
+	default:
+		return http.StatusInternalServerError, CodeInternal
+	}
+}
+
+// InvalidGuess describes one guess rejected by validation.
+type InvalidGuess struct {
+	Index   int    `json:"index"`
+	Guess   string `json:"guess"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newInvalidGuesses(errs wordle.GuessErrors) []InvalidGuess {
+	invalid := make([]InvalidGuess, len(errs))
+	for i, guessErr := range errs {
+		invalid[i] = InvalidGuess{
+			Index:   guessErr.Index,
+			Guess:   guessErr.Guess,
+			Code:    validationErrorCode(guessErr),
+			Message: guessErr.Error(),
+		}
+	}
+	return invalid
+}
+
+// writeValidationError reports a request rejected by the wordle validators
+// with 400. Invalid guesses are listed in Details under "invalid_guesses"; the
+// code is theirs if they all share one, and CodeInvalidGuesses otherwise.
+func writeValidationError(w http.ResponseWriter, err error) {
+	var guessErrs wordle.GuessErrors
+	if !errors.As(err, &guessErrs) {
+		writeError(w, http.StatusBadRequest, validationErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	invalid := newInvalidGuesses(guessErrs)
+	code := invalid[0].Code
+	for _, guess := range invalid {
+		if guess.Code != code {
+			code = CodeInvalidGuesses
+		}
+	}
+	writeError(w, http.StatusBadRequest, code, err.Error(), map[string]any{"invalid_guesses": invalid})
+}
+
+func validationErrorCode(err error) string {
+	switch {
+	case errors.Is(err, wordle.ErrInvalidGuessLength):
+		return CodeInvalidGuessCount
+	case errors.Is(err, wordle.ErrInvalidWordLength):
+		return CodeInvalidWordLength
+	case errors.Is(err, wordle.ErrWordNotInCorpus):
+		return CodeWordNotInCorpus
+	case errors.Is(err, wordle.ErrInvalidGameIndex):
+		return CodeInvalidGameIndex
+	case errors.Is(err, wordle.ErrHardModeViolation):
+		return CodeHardModeViolation
+	case errors.Is(err, wordle.ErrInvalidTeamId):
+		return CodeInvalidTeamID
+	default:
+		return CodeInvalidRequest
+	}
+}
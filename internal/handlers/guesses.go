@@ -1,105 +1,380 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/config"
 	"wordle-tournament-backend/internal/storage"
 	"wordle-tournament-backend/internal/wordle"
+	"wordle-tournament-backend/internal/wordle/corpus"
 )
 
+// GuessesRequest submits one round of guesses for a run owned by the team of
+// the request's API key. TeamId is optional and, if set, must match that team.
+//
+// Exactly one of Guesses and GuessMap must be set. Guesses is dense: one guess
+// per game, with DummyGuess for games that are already solved. GuessMap is
+// sparse: it maps game index to guess and only needs to cover the games being
+// played this round; games it leaves out are untouched.
 type GuessesRequest struct {
-	TeamId  string   `json:"team_id"`
-	RunId   string   `json:"run_id"`
-	Guesses []string `json:"guesses"`
+	TeamId   string         `json:"team_id,omitempty"`
+	RunId    string         `json:"run_id"`
+	Guesses  []string       `json:"guesses,omitempty"`
+	GuessMap map[int]string `json:"guess_map,omitempty"`
 }
 
+// GuessesResponse holds one hint per guess: Hints for a dense submission, or
+// HintMap keyed by game index for a sparse one. Games that have already failed
+// ignore their guess: it is not graded or counted, and its hint is empty.
+// When the server skips invalid guesses they are listed in InvalidGuesses and
+// treated the same way; otherwise they reject the whole submission.
+// Once every game is solved or failed the run is finalized: RunFinished is set,
+// Score holds the run's score and the run can no longer be played. A finished
+// practice run started with reveal_answers also lists every game's Answers.
 type GuessesResponse struct {
-	Hints []string `json:"hints"`
+	Hints          []string           `json:"hints,omitempty"`
+	HintMap        map[int]string     `json:"hint_map,omitempty"`
+	InvalidGuesses []InvalidGuess     `json:"invalid_guesses,omitempty"`
+	RunFinished    bool               `json:"run_finished"`
+	Score          *storage.ScoreItem `json:"score,omitempty"`
+	Answers        []string           `json:"answers,omitempty"`
 }
 
-func GuessesHandler() http.HandlerFunc {
+func GuessesHandler(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			handlePostGuesses(w, r)
+			handlePostGuesses(store, config.Get().SkipInvalidGuesses, w, r)
 		default:
-			http.Error(w, "HTTP Method not allowed", http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 		}
 	}
 }
 
-// Potential Issues:
-// - If the team_id + run_id are invalid, request returns 500 error when we should return something more helpful.
-// - No server-side validation on NumGuesses being less than MAX_GUESSSES (already in middleware)
-func handlePostGuesses(w http.ResponseWriter, r *http.Request) {
-	// TODO: uppercase guesses will FAIL
+// handlePostGuesses grades one round of guesses. If the request carries an
+// Idempotency-Key header that matches the run's last submission, the stored
+// response is returned without changing the run, so clients can safely retry.
+// Only the most recent submission is remembered. A finished run is moved to
+// the completed run store, so a retry of its final submission is answered from
+// there.
+//
+// Guesses are normalized with wordle.NormalizeGuess and then validated against
+// the run's corpus and number of games, and in hard mode against the earlier
+// hints of their game, so case and surrounding whitespace do not matter.
+//
+// An unknown or finished run is reported with 404 and an expired one with 410.
+// A finished run that is still in the run store was not finalized by the
+// submission that finished it, so it is finalized first.
+// If skipInvalid is set, invalid words and hard mode violations are left out
+// instead of rejecting the submission.
+func handlePostGuesses(store storage.Store, skipInvalid bool, w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Failed to read request body", nil)
+		return
+	}
+
 	var req GuessesRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid json body", http.StatusBadRequest)
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid json body", nil)
 		return
 	}
 
-	if req.TeamId == "" {
-		http.Error(w, "team_id cannot be empty", http.StatusBadRequest)
+	idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("%s cannot be longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength), nil)
+		return
+	}
+	requestHash := hashRequestBody(body)
+
+	teamID, ok := resolveTeamID(w, r, req.TeamId)
+	if !ok {
 		return
 	}
 
 	if req.RunId == "" {
-		http.Error(w, "run_id cannot be empty", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "run_id cannot be empty", nil)
+		return
+	}
+	if !checkRunID(w, req.RunId) {
 		return
 	}
 
-	if err := wordle.ValidateGuesses(req.Guesses); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	sparse := req.GuessMap != nil
+	if sparse == (req.Guesses != nil) {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "exactly one of guesses and guess_map must be set", nil)
 		return
 	}
 
-	activeRun, err := storage.GetActiveRun(req.TeamId, req.RunId)
+	activeRun, err := store.GetActiveRun(r.Context(), teamID, req.RunId)
 	if err != nil {
-		// Must distinguish between (team_id, run_id) being invalid and network issues causing the request to fail.
-		statusCode := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "expired or not found") {
-			statusCode = http.StatusBadRequest
+		if errors.Is(err, storage.ErrRunNotFound) && replayCompletedSubmission(r.Context(), store, w, teamID, req, idempotencyKey, requestHash) {
+			return
 		}
-		http.Error(w, err.Error(), statusCode)
+		writeStorageError(w, err)
 		return
 	}
 
-	// Extract answers from activeRun.Games
-	answers := make([]string, len(activeRun.Games))
-	for i := range activeRun.Games {
-		answers[i] = activeRun.Games[i].Answer
+	if activeRun.Finished() {
+		// The submission that finished the run was saved, but finalizing it
+		// failed. Finish the job before answering a retry of it.
+		if err := finalizeRun(r.Context(), store, activeRun, finishedRunScore(activeRun)); err != nil {
+			writeStorageError(w, err)
+			return
+		}
+		if !replaySubmission(w, activeRun, req, idempotencyKey, requestHash) {
+			writeError(w, http.StatusNotFound, CodeRunNotFound, fmt.Sprintf("run is already finished: run_id=%s", req.RunId), nil)
+		}
+		return
+	}
+
+	if replaySubmission(w, activeRun, req, idempotencyKey, requestHash) {
+		return
+	}
+
+	words, ok := corpus.Get(activeRun.Corpus)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternal, fmt.Sprintf("run uses unknown corpus %q", activeRun.Corpus), nil)
+		return
+	}
+
+	rules := wordle.Rules{
+		NumGames: len(activeRun.Games),
+		Corpus:   words,
+		HardMode: activeRun.HardMode,
+		History:  hardModeHistory(activeRun),
+		Solved:   func(index int) bool { return activeRun.Games[index].Solved },
+	}
+
+	var validationErr error
+	if sparse {
+		wordle.NormalizeGuessMap(req.GuessMap)
+		validationErr = wordle.ValidateGuessMap(req.GuessMap, rules)
+	} else {
+		wordle.NormalizeGuesses(req.Guesses)
+		validationErr = wordle.ValidateGuesses(req.Guesses, rules)
 	}
+	submitted := submittedGuesses(req)
 
-	hints := wordle.GradeGuesses(req.Guesses, answers)
+	var invalid wordle.GuessErrors
+	if validationErr != nil {
+		var guessErrs wordle.GuessErrors
+		if errors.As(validationErr, &guessErrs) {
+			truncateGuesses(guessErrs, words.WordLength)
+		}
 
-	for i, hint := range hints {
-		// If the guess is DummyGuess, the game is already solved
-		if req.Guesses[i] == common.DummyGuess {
-			activeRun.Games[i].Solved = true
+		var ok bool
+		if invalid, ok = skippableGuessErrors(validationErr); !ok || !skipInvalid {
+			writeValidationError(w, validationErr)
+			return
 		}
+	}
 
-		if req.Guesses[i] != common.DummyGuess && !activeRun.Games[i].Solved {
-			activeRun.Games[i].NumGuesses++
+	skipped := invalid.Indices()
+	var indices []int
+	var guesses []string
+	for _, index := range wordle.SortedIndices(submitted) {
+		if !skipped[index] {
+			indices = append(indices, index)
+			guesses = append(guesses, submitted[index])
 		}
+	}
+
+	// Extract the answers of the games being guessed from activeRun.Games
+	answers := make([]string, len(indices))
+	for k, index := range indices {
+		answers[k] = activeRun.Games[index].Answer
+	}
 
-		if hint == strings.Repeat("O", common.WordLength) {
-			activeRun.Games[i].Solved = true
+	hints, err := wordle.GradeGuesses(guesses, answers)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+		return
+	}
+
+	maxGuesses := activeRun.MaxGuesses
+	if maxGuesses == 0 {
+		maxGuesses = config.Get().MaxGuesses
+	}
+	for k, index := range indices {
+		hints[k] = applyGuess(&activeRun.Games[index], guesses[k], hints[k], maxGuesses)
+	}
+
+	var response GuessesResponse
+	if sparse {
+		response.HintMap = make(map[int]string, len(indices))
+		for k, index := range indices {
+			response.HintMap[index] = hints[k]
+		}
+	} else {
+		response.Hints = make([]string, len(req.Guesses))
+		for k, index := range indices {
+			response.Hints[index] = hints[k]
+		}
+	}
+	if len(invalid) > 0 {
+		response.InvalidGuesses = newInvalidGuesses(invalid)
+	}
+
+	if activeRun.Finished() {
+		score := storage.NewScore(activeRun, time.Now())
+		response.RunFinished = true
+		response.Score = &score
+		if activeRun.RevealAnswers {
+			response.Answers = make([]string, len(activeRun.Games))
+			for i, game := range activeRun.Games {
+				response.Answers[i] = game.Answer
+			}
 		}
 	}
 
-	if err := storage.PutActiveRun(activeRun); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+		return
+	}
+
+	if idempotencyKey != "" {
+		activeRun.LastSubmission = newSubmissionRecord(idempotencyKey, requestHash, submitted, response)
+	}
+
+	if err := store.PutActiveRun(r.Context(), activeRun); err != nil {
+		// Another submission for this run was applied after we read it. Rejecting
+		// ours keeps NumGuesses consistent; the client can resubmit.
+		writeStorageError(w, err)
 		return
 	}
 
-	response := GuessesResponse{
-		Hints: hints,
+	if response.RunFinished {
+		if err := finalizeRun(r.Context(), store, activeRun, response.Score); err != nil {
+			writeStorageError(w, err)
+			return
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	w.Write(responseBody)
+}
+
+// submittedGuesses returns the guesses of req by game index.
+func submittedGuesses(req GuessesRequest) map[int]string {
+	if req.GuessMap != nil {
+		return req.GuessMap
+	}
+	submitted := make(map[int]string, len(req.Guesses))
+	for i, guess := range req.Guesses {
+		submitted[i] = guess
+	}
+	return submitted
+}
+
+// truncateGuesses cuts the guesses of guessErrs that are longer than
+// wordLength letters, so responses echoing invalid guesses stay small however
+// long the submitted ones were.
+func truncateGuesses(guessErrs wordle.GuessErrors, wordLength int) {
+	for _, guessErr := range guessErrs {
+		if utf8.RuneCountInString(guessErr.Guess) > wordLength {
+			guessErr.Guess = string([]rune(guessErr.Guess)[:wordLength]) + "…"
+		}
+	}
+}
+
+// skippableGuessErrors returns the invalid guesses of a failed validation if
+// every one of them is a bad word or a hard mode violation, so the submission
+// can still be played with those slots left out. Wrong counts and game indices
+// are never skippable.
+func skippableGuessErrors(err error) (wordle.GuessErrors, bool) {
+	var guessErrs wordle.GuessErrors
+	if !errors.As(err, &guessErrs) || errors.Is(err, wordle.ErrInvalidGameIndex) {
+		return nil, false
+	}
+	return guessErrs, true
+}
+
+// hardModeHistory returns the wordle.Rules History of run: the counted
+// guesses of a game and their regraded hints. Finished games return nothing,
+// since their guesses are ignored.
+func hardModeHistory(run *storage.ActiveRunItem) func(int) ([]string, []string) {
+	return func(index int) ([]string, []string) {
+		game := run.Games[index]
+		if game.Solved || game.Failed {
+			return nil, nil
+		}
+
+		guesses := game.Guesses()
+		hints, err := wordle.GradeGuesses(guesses, slices.Repeat([]string{game.Answer}, len(guesses)))
+		if err != nil {
+			return nil, nil
+		}
+		return guesses, hints
+	}
+}
+
+// finalizeRun archives a finished run with its history, keeps its score in the
+// Scores table if it is the team's best in the run's tournament, and removes
+// the run from the run store. Practice runs, and ranked runs outside a
+// tournament, are archived but never scored.
+func finalizeRun(ctx context.Context, store storage.Store, activeRun *storage.ActiveRunItem, score *storage.ScoreItem) error {
+	if err := store.PutCompletedRun(ctx, activeRun); err != nil {
+		return err
+	}
+
+	if activeRun.IsScored() {
+		if _, err := store.PutBestScore(ctx, score); err != nil {
+			return err
+		}
+	}
+
+	return store.RemoveActiveRun(ctx, activeRun.TeamID, activeRun.RunID)
+}
+
+// finishedRunScore returns the score reported by the submission that finished
+// run, or a new one if that response was not stored.
+func finishedRunScore(run *storage.ActiveRunItem) *storage.ScoreItem {
+	if run.LastSubmission != nil && run.LastSubmission.Score != nil {
+		return run.LastSubmission.Score
+	}
+	score := storage.NewScore(run, time.Now())
+	return &score
+}
+
+// applyGuess updates game with a graded guess and returns the hint to report
+// for it. Failed games ignore the guess entirely. DummyGuess only stands in
+// for a game that is already solved: it is never counted, never changes the
+// game, and gets an empty hint if the game is not solved. Otherwise, an
+// unsolved game counts the guess and becomes Failed once it reaches
+// maxGuesses without being solved.
+func applyGuess(game *storage.GameState, guess, hint string, maxGuesses int) string {
+	if game.Failed {
+		return ""
+	}
+
+	if guess == common.DummyGuess {
+		if !game.Solved {
+			return ""
+		}
+		return hint
+	}
+
+	if !game.Solved {
+		game.RecordGuess(guess)
+	}
+
+	if hint == strings.Repeat("O", utf8.RuneCountInString(game.Answer)) {
+		game.Solved = true
+	} else if !game.Solved && game.NumGuesses >= maxGuesses {
+		game.Failed = true
+	}
+
+	return hint
 }
@@ -0,0 +1,624 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle/corpus"
+	"wordle-tournament-backend/internal/wordle/corpus/corpustest"
+)
+
+func postJSON(t *testing.T, handler http.HandlerFunc, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload)))
+	return rec
+}
+
+// asTeam runs next as if RequireTeam had authenticated teamID.
+func asTeam(teamID string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(withTeamID(r.Context(), teamID)))
+	}
+}
+
+// startRun starts a full ranked run in the open tournament.
+func startRun(t *testing.T, store storage.Store, teamID string) string {
+	t.Helper()
+	ensureOpenTournament(t, store)
+	rec := postJSON(t, asTeam(teamID, StartHandler(store)), "/start", StartRequest{TeamID: teamID, TournamentID: openTournamentID})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 from /start, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp StartResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode start response: %v", err)
+	}
+	return resp.RunID
+}
+
+func TestGuessesGradesAndUpdatesRun(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	run, err := store.GetActiveRun(context.Background(), "team", runID)
+	if err != nil {
+		t.Fatalf("GetActiveRun: %v", err)
+	}
+
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = common.DummyGuess
+	}
+	guesses[0] = run.Games[0].Answer
+	guesses[1] = "xylyl"
+	if guesses[1] == run.Games[1].Answer {
+		guesses[1] = "crane"
+	}
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		TeamId:  "team",
+		RunId:   runID,
+		Guesses: guesses,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Hints[0] != "OOOOO" {
+		t.Errorf("expected solved hint for game 0, got %q", resp.Hints[0])
+	}
+
+	after, _ := store.GetActiveRun(context.Background(), "team", runID)
+	if !after.Games[0].Solved || after.Games[0].NumGuesses != 1 {
+		t.Errorf("game 0 should be solved in one guess, got %+v", after.Games[0])
+	}
+	if after.Games[1].Solved || after.Games[1].NumGuesses != 1 {
+		t.Errorf("game 1 should be unsolved with one guess, got %+v", after.Games[1])
+	}
+}
+
+func TestGuessesUnknownRun(t *testing.T) {
+	store := storage.NewMemoryStore()
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = common.DummyGuess
+	}
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		TeamId:  "team",
+		RunId:   uuid.NewString(),
+		Guesses: guesses,
+	})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown run, got %d", rec.Code)
+	}
+	var resp ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Error.Code != CodeRunNotFound {
+		t.Errorf("expected code %q, got %q", CodeRunNotFound, resp.Error.Code)
+	}
+}
+
+func TestGuessesRejectsChunkRunID(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		RunId:    runID + "#games#0000",
+		GuessMap: map[int]string{0: "crane"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a game chunk's run_id, got %d", rec.Code)
+	}
+	if _, err := store.GetActiveRun(context.Background(), "team", runID); err != nil {
+		t.Errorf("run should be untouched, got %v", err)
+	}
+}
+
+func TestGuessesInvalidWordErrorCode(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		RunId:    runID,
+		GuessMap: map[int]string{3: "crane", 12: "xyzzy"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a JSON error, got Content-Type %q", ct)
+	}
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	if resp.Error.Code != CodeWordNotInCorpus {
+		t.Errorf("expected code %q, got %q", CodeWordNotInCorpus, resp.Error.Code)
+	}
+	invalid, _ := resp.Error.Details["invalid_guesses"].([]any)
+	if len(invalid) != 1 {
+		t.Fatalf("expected one invalid guess in details, got %v", resp.Error.Details)
+	}
+	if guess := invalid[0].(map[string]any); guess["index"] != float64(12) || guess["guess"] != "xyzzy" {
+		t.Errorf("expected details to name game 12, got %v", guess)
+	}
+}
+
+func TestGuessesReportsEveryInvalidWord(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		RunId:    runID,
+		GuessMap: map[int]string{3: "cranes", 5: "crane", 12: "xyzzy"},
+	})
+
+	var resp ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusBadRequest || resp.Error.Code != CodeInvalidGuesses {
+		t.Fatalf("expected 400 %s, got %d %s", CodeInvalidGuesses, rec.Code, resp.Error.Code)
+	}
+	if invalid, _ := resp.Error.Details["invalid_guesses"].([]any); len(invalid) != 2 {
+		t.Errorf("expected both invalid guesses in details, got %v", resp.Error.Details)
+	}
+}
+
+func TestGuessesSkipsInvalidWords(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	skipInvalid := func(w http.ResponseWriter, r *http.Request) {
+		handlePostGuesses(store, true, w, r)
+	}
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = "crane"
+	}
+	guesses[4] = "xyzzy"
+
+	rec := postJSON(t, asTeam("team", skipInvalid), "/api/guesses", GuessesRequest{RunId: runID, Guesses: guesses})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.InvalidGuesses) != 1 || resp.InvalidGuesses[0].Index != 4 || resp.InvalidGuesses[0].Code != CodeWordNotInCorpus {
+		t.Errorf("expected game 4 to be reported invalid, got %+v", resp.InvalidGuesses)
+	}
+	if len(resp.Hints) != common.NumTargetWords || resp.Hints[4] != "" || resp.Hints[3] == "" {
+		t.Errorf("expected an empty hint only for the skipped game")
+	}
+
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+	if run.Games[4].NumGuesses != 0 || run.Games[3].NumGuesses != 1 {
+		t.Errorf("skipped game should be untouched, got %+v and %+v", run.Games[4], run.Games[3])
+	}
+}
+
+func TestGuessesAcceptsMixedCase(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		RunId:    runID,
+		GuessMap: map[int]string{0: " " + strings.ToUpper(run.Games[0].Answer) + " "},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.HintMap[0] != "OOOOO" {
+		t.Errorf("expected the uppercase answer to solve game 0, got %q", resp.HintMap[0])
+	}
+
+	after, _ := store.GetActiveRun(context.Background(), "team", runID)
+	if got := after.Games[0].Guesses(); len(got) != 1 || got[0] != run.Games[0].Answer {
+		t.Errorf("expected the normalized guess in the history, got %v", got)
+	}
+}
+
+func TestApplyGuessFailsAfterMaxGuesses(t *testing.T) {
+	game := storage.GameState{Answer: "crane"}
+
+	for i := 0; i < 3; i++ {
+		applyGuess(&game, "house", "XXXXO", 3)
+	}
+	if !game.Failed || game.Solved || game.NumGuesses != 3 {
+		t.Fatalf("game should fail after 3 wrong guesses, got %+v", game)
+	}
+
+	hint := applyGuess(&game, "crane", "OOOOO", 3)
+	if hint != "" {
+		t.Errorf("failed game should return an empty hint, got %q", hint)
+	}
+	if game.Solved || game.NumGuesses != 3 {
+		t.Errorf("guesses for a failed game should be ignored, got %+v", game)
+	}
+
+	applyGuess(&game, common.DummyGuess, "OOOOO", 3)
+	if game.Solved {
+		t.Error("DummyGuess should not mark a failed game as solved")
+	}
+}
+
+func TestApplyGuessIgnoresDummyGuessForUnsolvedGame(t *testing.T) {
+	game := storage.GameState{Answer: "crane", NumGuesses: 1, History: "house"}
+
+	hint := applyGuess(&game, common.DummyGuess, "OOOOO", 3)
+	if hint != "" || game.Solved || game.NumGuesses != 1 {
+		t.Errorf("DummyGuess should not count or solve an unsolved game, got %q and %+v", hint, game)
+	}
+}
+
+func TestGuessesDummyGuessesDoNotFinishRun(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = common.DummyGuess
+	}
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: runID, Guesses: guesses})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.RunFinished || resp.Score != nil {
+		t.Errorf("DummyGuess should not finish the run, got %+v", resp)
+	}
+	run, err := store.GetActiveRun(context.Background(), "team", runID)
+	if err != nil || run.Games[0].Solved {
+		t.Errorf("run should still be active with unsolved games, got %+v (err %v)", run, err)
+	}
+	if best, _ := store.GetScore(context.Background(), openTournamentID, "team"); best != nil {
+		t.Errorf("DummyGuess should not score the run, got %+v", best)
+	}
+}
+
+func TestApplyGuessSolvedOnLastGuess(t *testing.T) {
+	game := storage.GameState{Answer: "crane", NumGuesses: 2}
+
+	applyGuess(&game, "crane", "OOOOO", 3)
+	if !game.Solved || game.Failed || game.NumGuesses != 3 {
+		t.Errorf("game should be solved on its last guess, got %+v", game)
+	}
+}
+
+func TestGuessesFinalizesFinishedRun(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = run.Games[i].Answer
+	}
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		TeamId:  "team",
+		RunId:   runID,
+		Guesses: guesses,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !resp.RunFinished || resp.Score == nil {
+		t.Fatalf("expected a finished run with a score, got %+v", resp)
+	}
+	if resp.Score.NumSolved != common.NumTargetWords || resp.Score.TotalGuesses != common.NumTargetWords {
+		t.Errorf("unexpected score %+v", resp.Score)
+	}
+
+	best, err := store.GetScore(context.Background(), openTournamentID, "team")
+	if err != nil || best == nil || best.RunID != runID || best.TournamentID != openTournamentID {
+		t.Errorf("expected finished run to be the team's best score, got %+v (err %v)", best, err)
+	}
+
+	if _, err := store.GetActiveRun(context.Background(), "team", runID); err == nil {
+		t.Error("finished run should be removed from the run store")
+	}
+}
+
+func TestGuessesSparseSubmission(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{
+		RunId:    runID,
+		GuessMap: map[int]string{7: run.Games[7].Answer, 42: "xylyl"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Hints != nil || len(resp.HintMap) != 2 || resp.HintMap[7] != "OOOOO" {
+		t.Errorf("expected a sparse hint map for games 7 and 42, got %+v", resp)
+	}
+
+	after, _ := store.GetActiveRun(context.Background(), "team", runID)
+	if !after.Games[7].Solved || after.Games[42].NumGuesses != 1 {
+		t.Errorf("games 7 and 42 should have been graded, got %+v and %+v", after.Games[7], after.Games[42])
+	}
+	if after.Games[0].NumGuesses != 0 || after.Games[0].Solved {
+		t.Errorf("games outside the guess map should be untouched, got %+v", after.Games[0])
+	}
+}
+
+func TestGuessesRequiresExactlyOneFormat(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	for _, req := range []GuessesRequest{
+		{RunId: runID},
+		{RunId: runID, Guesses: []string{"crane"}, GuessMap: map[int]string{0: "crane"}},
+	} {
+		rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	}
+}
+
+func TestStartWithSeedIsReproducible(t *testing.T) {
+	store := storage.NewMemoryStore()
+	seed := int64(1234)
+
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, Seed: &seed})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	var resp StartResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	run, _ := store.GetActiveRun(context.Background(), "team", resp.RunID)
+
+	if run.Seed != seed {
+		t.Errorf("expected run to record seed %d, got %d", seed, run.Seed)
+	}
+
+	regenerated := storage.GenerateGameStates(corpus.Default(), seed, common.NumTargetWords)
+	for i := range run.Games {
+		if run.Games[i].Answer != regenerated[i].Answer {
+			t.Fatalf("game %d does not match the regenerated answer set", i)
+		}
+	}
+}
+
+func TestStartRankedRejectsPracticeOptions(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ensureOpenTournament(t, store)
+	seed := int64(1234)
+
+	for _, req := range []StartRequest{
+		{},
+		{Seed: &seed, TournamentID: openTournamentID},
+		{Mode: storage.ModeRanked, RevealAnswers: true, TournamentID: openTournamentID},
+		{NumGames: 1, TournamentID: openTournamentID},
+		{Corpus: corpus.DefaultName, TournamentID: openTournamentID},
+		{Mode: storage.ModePractice, NumGames: common.NumTargetWords + 1},
+		{Mode: storage.ModePractice, Corpus: "missing"},
+		{Mode: "casual"},
+	} {
+		rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %+v, got %d", req, rec.Code)
+		}
+	}
+}
+
+func TestPracticeRunIsNotScored(t *testing.T) {
+	store := storage.NewMemoryStore()
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, RevealAnswers: true})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	var start StartResponse
+	json.NewDecoder(rec.Body).Decode(&start)
+	if start.Mode != storage.ModePractice {
+		t.Errorf("expected mode %q, got %q", storage.ModePractice, start.Mode)
+	}
+
+	run, _ := store.GetActiveRun(context.Background(), "team", start.RunID)
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = run.Games[i].Answer
+	}
+
+	rec = postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: guesses})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !resp.RunFinished || !reflect.DeepEqual(resp.Answers, guesses) {
+		t.Errorf("expected the finished practice run to reveal its answers, got %+v", resp)
+	}
+
+	if best, _ := store.GetScore(context.Background(), "", "team"); best != nil {
+		t.Errorf("practice run should not be scored, got %+v", best)
+	}
+	if completed, _ := store.GetCompletedRun(context.Background(), "team", start.RunID); completed == nil || !completed.IsPractice() {
+		t.Errorf("practice run should be archived as a practice run, got %+v", completed)
+	}
+}
+
+func TestSmallPracticeRun(t *testing.T) {
+	store := storage.NewMemoryStore()
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, NumGames: 3})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	var start StartResponse
+	json.NewDecoder(rec.Body).Decode(&start)
+	run, _ := store.GetActiveRun(context.Background(), "team", start.RunID)
+	if start.NumGames != 3 || len(run.Games) != 3 || run.NumGames != 3 {
+		t.Fatalf("expected a 3-game run, got %d games (response %d)", len(run.Games), start.NumGames)
+	}
+
+	full := make([]string, common.NumTargetWords)
+	for i := range full {
+		full[i] = common.DummyGuess
+	}
+	rec = postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: full})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a guess per game of a full run, got %d", rec.Code)
+	}
+	rec = postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, GuessMap: map[int]string{3: "crane"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a game index past the run, got %d", rec.Code)
+	}
+
+	guesses := []string{run.Games[0].Answer, run.Games[1].Answer, run.Games[2].Answer}
+	rec = postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: guesses})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !resp.RunFinished || resp.Score.NumSolved != 3 {
+		t.Errorf("expected the 3-game run to be finished with 3 solved, got %+v", resp)
+	}
+}
+
+func TestPracticeRunWithOtherCorpus(t *testing.T) {
+	corpustest.Load(t, "hand4", "baum haus maus", "haus maus")
+
+	store := storage.NewMemoryStore()
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, Corpus: "hand4"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var start StartResponse
+	json.NewDecoder(rec.Body).Decode(&start)
+	if start.Corpus != "hand4" || start.WordLength != 4 || start.NumGames != 2 {
+		t.Fatalf("expected a 2-game run of 4-letter words, got %+v", start)
+	}
+
+	rec = postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: []string{"crane", "baum"}})
+	var errResp ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&errResp)
+	if rec.Code != http.StatusBadRequest || errResp.Error.Code != CodeInvalidWordLength {
+		t.Errorf("expected 400 %s for a 5-letter guess, got %d %s", CodeInvalidWordLength, rec.Code, errResp.Error.Code)
+	}
+
+	rec = postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: []string{"baum", "baum"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	slices.Sort(resp.Hints)
+	if !slices.Equal(resp.Hints, []string{"XOOX", "XOO~"}) {
+		t.Errorf("expected baum graded against haus and maus, got %q", resp.Hints)
+	}
+
+	run, _ := store.GetActiveRun(context.Background(), "team", start.RunID)
+	if run.Corpus != "hand4" {
+		t.Errorf("run should record its corpus, got %q", run.Corpus)
+	}
+}
+
+func TestHardModeRejectsGuessesIgnoringHints(t *testing.T) {
+	store := storage.NewMemoryStore()
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, NumGames: 1, HardMode: true})
+	var start StartResponse
+	json.NewDecoder(rec.Body).Decode(&start)
+	if rec.Code != http.StatusCreated || !start.HardMode {
+		t.Fatalf("expected a hard mode run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	run, _ := store.GetActiveRun(context.Background(), "team", start.RunID)
+	answer := run.Games[0].Answer
+
+	// The first guess shares a letter with the answer, the second none at all.
+	var first, second string
+	for _, word := range corpus.Default().Answers() {
+		shared := strings.ContainsAny(word, answer)
+		if first == "" && shared && word != answer {
+			first = word
+		} else if second == "" && !shared {
+			second = word
+		}
+	}
+
+	guess := func(word string) *httptest.ResponseRecorder {
+		return postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: []string{word}})
+	}
+	if rec := guess(first); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first guess to be played, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = guess(second)
+	var errResp ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&errResp)
+	if rec.Code != http.StatusBadRequest || errResp.Error.Code != CodeHardModeViolation {
+		t.Fatalf("expected 400 %s for %q after %q, got %d %s", CodeHardModeViolation, second, first, rec.Code, errResp.Error.Code)
+	}
+
+	if rec := guess(answer); rec.Code != http.StatusOK {
+		t.Errorf("expected the answer to be allowed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHardModeOnlyAllowsDummyGuessForSolvedGames(t *testing.T) {
+	store := storage.NewMemoryStore()
+	rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, NumGames: 2, HardMode: true})
+	var start StartResponse
+	json.NewDecoder(rec.Body).Decode(&start)
+	run, _ := store.GetActiveRun(context.Background(), "team", start.RunID)
+
+	guess := func(guesses ...string) *httptest.ResponseRecorder {
+		return postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: start.RunID, Guesses: guesses})
+	}
+
+	rec = guess(common.DummyGuess, run.Games[1].Answer)
+	var errResp ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&errResp)
+	if rec.Code != http.StatusBadRequest || errResp.Error.Code != CodeHardModeViolation {
+		t.Fatalf("expected 400 %s for DummyGuess on an unsolved game, got %d %s", CodeHardModeViolation, rec.Code, errResp.Error.Code)
+	}
+	if after, _ := store.GetActiveRun(context.Background(), "team", start.RunID); after.Games[0].Solved || after.Games[1].Solved {
+		t.Fatalf("rejected submission should not change the run, got %+v", after.Games)
+	}
+
+	if rec := guess(run.Games[0].Answer, run.Games[0].Answer); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := guess(common.DummyGuess, run.Games[1].Answer); rec.Code != http.StatusOK {
+		t.Errorf("expected DummyGuess to be allowed for the solved game, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
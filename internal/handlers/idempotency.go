@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle"
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+const (
+	// IdempotencyKeyHeader lets a client mark a guess submission so that a
+	// retry of it is answered from the stored response instead of regraded.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed from a stored submission.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// hashRequestBody fingerprints a submission so that reusing an idempotency key
+// with a different request can be detected.
+func hashRequestBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// skippedGuessErrors maps the code of a skipped guess back to the error it
+// was skipped for, so a replayed response can list it again.
+var skippedGuessErrors = map[string]error{
+	CodeInvalidWordLength: wordle.ErrInvalidWordLength,
+	CodeWordNotInCorpus:   wordle.ErrWordNotInCorpus,
+	CodeHardModeViolation: wordle.ErrHardModeViolation,
+}
+
+// newSubmissionRecord returns the record of a submission of the guesses in
+// submitted that was answered with response.
+func newSubmissionRecord(idempotencyKey, requestHash string, submitted map[int]string, response GuessesResponse) *storage.SubmissionRecord {
+	indices := wordle.SortedIndices(submitted)
+	hints := make([]string, len(indices))
+	for k, index := range indices {
+		if response.HintMap != nil {
+			hints[k] = response.HintMap[index]
+		} else {
+			hints[k] = response.Hints[index]
+		}
+	}
+
+	record := &storage.SubmissionRecord{
+		IdempotencyKey: idempotencyKey,
+		RequestHash:    requestHash,
+		Hints:          strings.Join(hints, ","),
+		RunFinished:    response.RunFinished,
+		Score:          response.Score,
+	}
+	for _, invalid := range response.InvalidGuesses {
+		if record.Invalid == nil {
+			record.Invalid = make(map[string][]int)
+		}
+		record.Invalid[invalid.Code] = append(record.Invalid[invalid.Code], invalid.Index)
+	}
+	return record
+}
+
+// replayedResponse rebuilds the response to the submission of req recorded
+// in run.LastSubmission. Skipped guesses are listed with their code, but
+// their message no longer says which hard mode rule was broken.
+func replayedResponse(run *storage.ActiveRunItem, req GuessesRequest) (GuessesResponse, error) {
+	record := run.LastSubmission
+	words, ok := corpus.Get(run.Corpus)
+	if !ok {
+		return GuessesResponse{}, fmt.Errorf("run uses unknown corpus %q", run.Corpus)
+	}
+
+	if req.GuessMap != nil {
+		wordle.NormalizeGuessMap(req.GuessMap)
+	} else {
+		wordle.NormalizeGuesses(req.Guesses)
+	}
+	submitted := submittedGuesses(req)
+	indices := wordle.SortedIndices(submitted)
+	hints := strings.Split(record.Hints, ",")
+	if len(hints) != len(indices) {
+		return GuessesResponse{}, fmt.Errorf("stored submission has %d hints for %d guesses", len(hints), len(indices))
+	}
+
+	var invalid wordle.GuessErrors
+	for code, codeIndices := range record.Invalid {
+		err, ok := skippedGuessErrors[code]
+		if !ok {
+			err = errors.New(code)
+		}
+		for _, index := range codeIndices {
+			invalid = append(invalid, &wordle.GuessError{Index: index, Guess: submitted[index], Err: err})
+		}
+	}
+	sort.Slice(invalid, func(i, j int) bool { return invalid[i].Index < invalid[j].Index })
+	skipped := invalid.Indices()
+
+	var response GuessesResponse
+	if req.GuessMap != nil {
+		response.HintMap = make(map[int]string, len(indices)-len(skipped))
+		for k, index := range indices {
+			if !skipped[index] {
+				response.HintMap[index] = hints[k]
+			}
+		}
+	} else {
+		response.Hints = hints
+	}
+	if len(invalid) > 0 {
+		truncateGuesses(invalid, words.WordLength)
+		response.InvalidGuesses = newInvalidGuesses(invalid)
+	}
+
+	if record.RunFinished {
+		response.RunFinished = true
+		response.Score = record.Score
+		if run.RevealAnswers {
+			response.Answers = make([]string, len(run.Games))
+			for i, game := range run.Games {
+				response.Answers[i] = game.Answer
+			}
+		}
+	}
+	return response, nil
+}
+
+// replaySubmission answers a request whose idempotency key matches the run's
+// last submission. It reports false if the key does not match, in which case
+// the request should be processed normally.
+func replaySubmission(w http.ResponseWriter, activeRun *storage.ActiveRunItem, req GuessesRequest, idempotencyKey, requestHash string) bool {
+	record := activeRun.LastSubmission
+	if idempotencyKey == "" || record == nil || record.IdempotencyKey != idempotencyKey {
+		return false
+	}
+
+	if record.RequestHash != requestHash {
+		writeError(w, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "Idempotency-Key was already used for a different request", nil)
+		return true
+	}
+
+	response, err := replayedResponse(activeRun, req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+		return true
+	}
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+		return true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBody)
+	return true
+}
+
+// replayCompletedSubmission answers a retry of the submission that finished a
+// run, which is no longer in the run store. It reports false if there is no
+// matching completed run, in which case the run is reported as not found.
+func replayCompletedSubmission(ctx context.Context, store storage.CompletedRunStore, w http.ResponseWriter, teamID string, req GuessesRequest, idempotencyKey, requestHash string) bool {
+	if idempotencyKey == "" {
+		return false
+	}
+
+	completedRun, err := store.GetCompletedRun(ctx, teamID, req.RunId)
+	if err != nil || completedRun == nil {
+		return false
+	}
+
+	return replaySubmission(w, completedRun, req, idempotencyKey, requestHash)
+}
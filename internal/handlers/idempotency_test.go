@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/storage"
+)
+
+func postGuessesWithKey(t *testing.T, store storage.Store, idempotencyKey string, req GuessesRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/guesses", bytes.NewReader(payload))
+	httpReq.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+
+	rec := httptest.NewRecorder()
+	asTeam("team", GuessesHandler(store))(rec, httpReq)
+	return rec
+}
+
+func TestIdempotentRetryIsNotRegraded(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	req := GuessesRequest{RunId: runID, GuessMap: map[int]string{0: "xylyl", 1: "crane"}}
+
+	first := postGuessesWithKey(t, store, "round-1", req)
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", first.Code, first.Body.String())
+	}
+
+	retry := postGuessesWithKey(t, store, "round-1", req)
+	if retry.Code != http.StatusOK || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected a replayed 200, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if !bytes.Equal(first.Body.Bytes(), retry.Body.Bytes()) {
+		t.Errorf("replayed body differs:\n%s\n%s", first.Body.String(), retry.Body.String())
+	}
+
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+	if run.Games[0].NumGuesses != 1 || run.Games[1].NumGuesses != 1 {
+		t.Errorf("retry should not count guesses again, got %+v and %+v", run.Games[0], run.Games[1])
+	}
+
+	next := postGuessesWithKey(t, store, "round-2", req)
+	if next.Code != http.StatusOK || next.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("a new key should be graded, got %d", next.Code)
+	}
+	run, _ = store.GetActiveRun(context.Background(), "team", runID)
+	if run.Games[0].NumGuesses != 2 {
+		t.Errorf("a new key should count guesses, got %+v", run.Games[0])
+	}
+}
+
+func TestIdempotencyKeyReusedWithDifferentRequest(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	postGuessesWithKey(t, store, "round-1", GuessesRequest{RunId: runID, GuessMap: map[int]string{0: "xylyl"}})
+	rec := postGuessesWithKey(t, store, "round-1", GuessesRequest{RunId: runID, GuessMap: map[int]string{0: "crane"}})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+}
+
+func TestIdempotentRetryOfFinishingSubmission(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+
+	guesses := make([]string, len(run.Games))
+	for i := range guesses {
+		guesses[i] = run.Games[i].Answer
+	}
+	req := GuessesRequest{RunId: runID, Guesses: guesses}
+
+	first := postGuessesWithKey(t, store, "final", req)
+	retry := postGuessesWithKey(t, store, "final", req)
+	if retry.Code != http.StatusOK || !bytes.Equal(first.Body.Bytes(), retry.Body.Bytes()) {
+		t.Errorf("retry of the finishing submission should replay it, got %d: %s", retry.Code, retry.Body.String())
+	}
+}
+
+func TestIdempotentRetryOfSkippedGuesses(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	skipInvalid := func(w http.ResponseWriter, r *http.Request) {
+		handlePostGuesses(store, true, w, r)
+	}
+	post := func(idempotencyKey string, req GuessesRequest) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/guesses", bytes.NewReader(payload))
+		httpReq.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		rec := httptest.NewRecorder()
+		asTeam("team", skipInvalid)(rec, httpReq)
+		return rec
+	}
+
+	for round, req := range []GuessesRequest{
+		{RunId: runID, GuessMap: map[int]string{0: "CRANE", 3: "xyzzy", 5: strings.Repeat("q", 1000)}},
+		{RunId: runID, Guesses: slices.Repeat([]string{"slate"}, common.NumTargetWords)},
+	} {
+		if req.Guesses != nil {
+			req.Guesses[7] = " Xyzzy "
+		}
+		idempotencyKey := fmt.Sprintf("round-%d", round)
+		first := post(idempotencyKey, req)
+		if first.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", first.Code, first.Body.String())
+		}
+		if strings.Contains(first.Body.String(), strings.Repeat("q", 6)) {
+			t.Errorf("invalid guesses should be echoed cut to the word length: %s", first.Body.String())
+		}
+		retry := post(idempotencyKey, req)
+		if retry.Header().Get(IdempotentReplayedHeader) != "true" || !bytes.Equal(first.Body.Bytes(), retry.Body.Bytes()) {
+			t.Errorf("replayed body differs:\n%s\n%s", first.Body.String(), retry.Body.String())
+		}
+
+		run, _ := store.GetActiveRun(context.Background(), "team", runID)
+		if len(run.LastSubmission.Hints) > 6*len(submittedGuesses(req)) {
+			t.Errorf("stored hints are not compact: %q", run.LastSubmission.Hints)
+		}
+	}
+}
+
+// flakyScoreStore fails the first PutBestScore, as if the Scores table were
+// briefly unavailable.
+type flakyScoreStore struct {
+	storage.Store
+	failed bool
+}
+
+func (s *flakyScoreStore) PutBestScore(ctx context.Context, score *storage.ScoreItem) (bool, error) {
+	if !s.failed {
+		s.failed = true
+		return false, errors.New("scores unavailable")
+	}
+	return s.Store.PutBestScore(ctx, score)
+}
+
+func TestIdempotentRetryFinalizesRun(t *testing.T) {
+	store := &flakyScoreStore{Store: storage.NewMemoryStore()}
+	runID := startRun(t, store, "team")
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+
+	guesses := make([]string, len(run.Games))
+	for i := range guesses {
+		guesses[i] = run.Games[i].Answer
+	}
+	req := GuessesRequest{RunId: runID, Guesses: guesses}
+
+	if first := postGuessesWithKey(t, store, "final", req); first.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the failed finalization to be reported, got %d: %s", first.Code, first.Body.String())
+	}
+
+	retry := postGuessesWithKey(t, store, "final", req)
+	var resp GuessesResponse
+	json.NewDecoder(retry.Body).Decode(&resp)
+	if retry.Code != http.StatusOK || !resp.RunFinished {
+		t.Fatalf("expected the retry to replay the finished run, got %d", retry.Code)
+	}
+
+	best, err := store.GetScore(context.Background(), openTournamentID, "team")
+	if err != nil || best == nil || best.RunID != runID || best.SubmittedAt != resp.Score.SubmittedAt {
+		t.Errorf("retry should store the replayed score, got %+v (err %v)", best, err)
+	}
+	if _, err := store.GetActiveRun(context.Background(), "team", runID); err == nil {
+		t.Error("retry should remove the finished run from the run store")
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"wordle-tournament-backend/internal/storage"
+)
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 100
+)
+
+// LeaderboardEntry is a team's best score in a tournament together with its
+// rank, starting at 1.
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	storage.ScoreItem
+}
+
+// LeaderboardResponse is one page of a tournament's leaderboard. Total is the
+// number of ranked teams across all pages.
+type LeaderboardResponse struct {
+	TournamentID string             `json:"tournament_id"`
+	Entries      []LeaderboardEntry `json:"entries"`
+	Total        int                `json:"total"`
+	Limit        int                `json:"limit"`
+	Offset       int                `json:"offset"`
+}
+
+func LeaderboardHandler(store storage.ScoreStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetLeaderboard(store, w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	}
+}
+
+// handleGetLeaderboard ranks every team's best score in the tournament given
+// by the required tournament_id query parameter. The limit and offset query
+// parameters select a page; team_id instead returns only that team's entry.
+func handleGetLeaderboard(store storage.ScoreStore, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tournamentID := query.Get("tournament_id")
+	if tournamentID == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "tournament_id cannot be empty", nil)
+		return
+	}
+
+	limit, err := parseQueryInt(query.Get("limit"), defaultLeaderboardLimit)
+	if err != nil || limit < 1 || limit > maxLeaderboardLimit {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxLeaderboardLimit), nil)
+		return
+	}
+
+	offset, err := parseQueryInt(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "offset must be a non-negative integer", nil)
+		return
+	}
+
+	scores, err := store.ListScores(r.Context(), tournamentID)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	storage.SortScores(scores)
+
+	entries := make([]LeaderboardEntry, len(scores))
+	for i, score := range scores {
+		entries[i] = LeaderboardEntry{Rank: i + 1, ScoreItem: score}
+	}
+
+	response := LeaderboardResponse{
+		TournamentID: tournamentID,
+		Entries:      []LeaderboardEntry{},
+		Total:        len(entries),
+		Limit:        limit,
+		Offset:       offset,
+	}
+
+	if teamID := query.Get("team_id"); teamID != "" {
+		for _, entry := range entries {
+			if entry.TeamID == teamID {
+				response.Entries = append(response.Entries, entry)
+				break
+			}
+		}
+		if len(response.Entries) == 0 {
+			writeError(w, http.StatusNotFound, CodeScoreNotFound, fmt.Sprintf("no score for team_id=%s in tournament_id=%s", teamID, tournamentID), nil)
+			return
+		}
+	} else if offset < len(entries) {
+		response.Entries = entries[offset:min(offset+limit, len(entries))]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseQueryInt parses a query parameter, returning defaultValue if it is empty.
+func parseQueryInt(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
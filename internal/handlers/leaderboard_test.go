@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wordle-tournament-backend/internal/storage"
+)
+
+func getLeaderboard(t *testing.T, store storage.ScoreStore, target string) (*httptest.ResponseRecorder, LeaderboardResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	LeaderboardHandler(store)(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp LeaderboardResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode leaderboard response: %v", err)
+		}
+	}
+	return rec, resp
+}
+
+func newLeaderboardStore() storage.ScoreStore {
+	store := storage.NewMemoryScoreStore()
+	for _, score := range []storage.ScoreItem{
+		{TournamentID: "spring", TeamID: "slow", NumSolved: 2315, TotalGuesses: 9000, SubmittedAt: 1},
+		{TournamentID: "spring", TeamID: "late", NumSolved: 2315, TotalGuesses: 8000, SubmittedAt: 5},
+		{TournamentID: "spring", TeamID: "early", NumSolved: 2315, TotalGuesses: 8000, SubmittedAt: 3},
+		{TournamentID: "spring", TeamID: "failing", NumSolved: 2300, TotalGuesses: 7000, SubmittedAt: 1},
+		{TournamentID: "autumn", TeamID: "slow", NumSolved: 10, TotalGuesses: 40, SubmittedAt: 1},
+	} {
+		store.PutBestScore(context.Background(), &score)
+	}
+	return store
+}
+
+func TestLeaderboardRanksTeams(t *testing.T) {
+	_, resp := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=spring")
+
+	want := []string{"early", "late", "slow", "failing"}
+	if resp.Total != len(want) || len(resp.Entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), resp)
+	}
+
+	for i, teamID := range want {
+		if resp.Entries[i].TeamID != teamID || resp.Entries[i].Rank != i+1 {
+			t.Errorf("entry %d: expected %s at rank %d, got %s at rank %d",
+				i, teamID, i+1, resp.Entries[i].TeamID, resp.Entries[i].Rank)
+		}
+	}
+}
+
+func TestLeaderboardPagination(t *testing.T) {
+	_, resp := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=spring&limit=2&offset=1")
+
+	if len(resp.Entries) != 2 || resp.Entries[0].TeamID != "late" || resp.Entries[1].Rank != 3 {
+		t.Errorf("unexpected page %+v", resp.Entries)
+	}
+
+	_, resp = getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=spring&offset=10")
+	if len(resp.Entries) != 0 || resp.Total != 4 {
+		t.Errorf("expected an empty page past the end, got %+v", resp)
+	}
+
+	rec, _ := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=spring&limit=0")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for limit=0, got %d", rec.Code)
+	}
+}
+
+func TestLeaderboardTeamFilter(t *testing.T) {
+	_, resp := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=spring&team_id=slow")
+	if len(resp.Entries) != 1 || resp.Entries[0].TeamID != "slow" || resp.Entries[0].Rank != 3 {
+		t.Errorf("expected slow at rank 3, got %+v", resp.Entries)
+	}
+
+	rec, _ := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=spring&team_id=missing")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a team without a score, got %d", rec.Code)
+	}
+}
+
+func TestLeaderboardIsPerTournament(t *testing.T) {
+	_, resp := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard?tournament_id=autumn")
+	if resp.TournamentID != "autumn" || resp.Total != 1 || resp.Entries[0].TeamID != "slow" || resp.Entries[0].Rank != 1 {
+		t.Errorf("expected only slow's autumn score, got %+v", resp)
+	}
+
+	rec, _ := getLeaderboard(t, newLeaderboardStore(), "/api/leaderboard")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without tournament_id, got %d", rec.Code)
+	}
+}
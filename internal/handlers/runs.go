@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle"
+)
+
+// GameStatus is the progress of a single game. It never includes the answer.
+type GameStatus struct {
+	Solved     bool `json:"solved"`
+	Failed     bool `json:"failed"`
+	NumGuesses int  `json:"num_guesses"`
+}
+
+// RunStatusResponse summarizes an active run so a bot can resume it.
+type RunStatusResponse struct {
+	TeamID           string       `json:"team_id"`
+	RunID            string       `json:"run_id"`
+	NumSolved        int          `json:"num_solved"`
+	NumUnsolved      int          `json:"num_unsolved"`
+	NumFailed        int          `json:"num_failed"`
+	TotalGuesses     int          `json:"total_guesses"`
+	ExpiresInSeconds int64        `json:"expires_in_seconds"`
+	Games            []GameStatus `json:"games"`
+}
+
+// checkRunID writes a 400 response and returns false if runID is not one that
+// /api/start could have handed out.
+func checkRunID(w http.ResponseWriter, runID string) bool {
+	if !storage.ValidRunID(runID) {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("run_id is not a valid run ID: %q", runID), nil)
+		return false
+	}
+	return true
+}
+
+func RunStatusHandler(store storage.RunStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetRunStatus(store, w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	}
+}
+
+// handleGetRunStatus serves GET /api/runs/{team_id}/{run_id}. Teams can only
+// read their own runs.
+func handleGetRunStatus(store storage.RunStore, w http.ResponseWriter, r *http.Request) {
+	teamID, ok := resolveTeamID(w, r, r.PathValue("team_id"))
+	if !ok {
+		return
+	}
+	runID := r.PathValue("run_id")
+	if !checkRunID(w, runID) {
+		return
+	}
+
+	activeRun, err := store.GetActiveRun(r.Context(), teamID, runID)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	response := RunStatusResponse{
+		TeamID:           activeRun.TeamID,
+		RunID:            activeRun.RunID,
+		ExpiresInSeconds: max(activeRun.TTL-time.Now().Unix(), 0),
+		Games:            make([]GameStatus, len(activeRun.Games)),
+	}
+
+	for i, game := range activeRun.Games {
+		switch {
+		case game.Solved:
+			response.NumSolved++
+		case game.Failed:
+			response.NumFailed++
+		default:
+			response.NumUnsolved++
+		}
+		response.TotalGuesses += game.NumGuesses
+
+		response.Games[i] = GameStatus{
+			Solved:     game.Solved,
+			Failed:     game.Failed,
+			NumGuesses: game.NumGuesses,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GameHistory is every counted guess of a game with its hint. Answer is only
+// set for organizers, or once a practice run started with reveal_answers is
+// completed.
+type GameHistory struct {
+	Solved  bool     `json:"solved"`
+	Failed  bool     `json:"failed"`
+	Guesses []string `json:"guesses"`
+	Hints   []string `json:"hints"`
+	Answer  string   `json:"answer,omitempty"`
+}
+
+// RunHistoryResponse is the guess history of an active or completed run.
+type RunHistoryResponse struct {
+	TeamID    string        `json:"team_id"`
+	RunID     string        `json:"run_id"`
+	Completed bool          `json:"completed"`
+	Games     []GameHistory `json:"games"`
+}
+
+// RunHistoryHandler serves GET /api/runs/{team_id}/{run_id}/history for the
+// authenticated team. Answers are not included unless the run revealed them.
+func RunHistoryHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			teamID, ok := resolveTeamID(w, r, r.PathValue("team_id"))
+			if !ok {
+				return
+			}
+			handleGetRunHistory(r.Context(), store, w, teamID, r.PathValue("run_id"), false)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	}
+}
+
+// AdminRunHistoryHandler serves GET /admin/runs/{team_id}/{run_id}/history so
+// organizers can audit any team's run. Answers are included.
+func AdminRunHistoryHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetRunHistory(r.Context(), store, w, r.PathValue("team_id"), r.PathValue("run_id"), true)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	}
+}
+
+// handleGetRunHistory looks the run up in the run store first and falls back
+// to the completed run store, then regrades every recorded guess.
+func handleGetRunHistory(ctx context.Context, store storage.Store, w http.ResponseWriter, teamID, runID string, includeAnswers bool) {
+	if !checkRunID(w, runID) {
+		return
+	}
+
+	run, err := store.GetActiveRun(ctx, teamID, runID)
+	completed := false
+	if err != nil {
+		if !errors.Is(err, storage.ErrRunNotFound) {
+			writeStorageError(w, err)
+			return
+		}
+
+		run, err = store.GetCompletedRun(ctx, teamID, runID)
+		if err != nil {
+			writeStorageError(w, err)
+			return
+		}
+		if run == nil {
+			writeError(w, http.StatusNotFound, CodeRunNotFound, fmt.Sprintf("run not found for team_id=%s, run_id=%s", teamID, runID), nil)
+			return
+		}
+		completed = true
+	}
+
+	response := RunHistoryResponse{
+		TeamID:    run.TeamID,
+		RunID:     run.RunID,
+		Completed: completed,
+		Games:     make([]GameHistory, len(run.Games)),
+	}
+
+	for i, game := range run.Games {
+		guesses := game.Guesses()
+		answers := make([]string, len(guesses))
+		for k := range answers {
+			answers[k] = game.Answer
+		}
+
+		hints, err := wordle.GradeGuesses(guesses, answers)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, fmt.Sprintf("game %d: %v", i, err), nil)
+			return
+		}
+
+		response.Games[i] = GameHistory{
+			Solved:  game.Solved,
+			Failed:  game.Failed,
+			Guesses: guesses,
+			Hints:   hints,
+		}
+		if includeAnswers || (completed && run.RevealAnswers) {
+			response.Games[i].Answer = game.Answer
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
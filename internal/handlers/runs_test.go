@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/storage"
+)
+
+func getRunStatus(store storage.RunStore, teamID, runID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/runs/"+teamID+"/"+runID, nil)
+	req.SetPathValue("team_id", teamID)
+	req.SetPathValue("run_id", runID)
+
+	rec := httptest.NewRecorder()
+	asTeam("team", RunStatusHandler(store))(rec, req)
+	return rec
+}
+
+func TestRunStatusReportsProgress(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+	run.Games[0].Solved = true
+	run.Games[0].NumGuesses = 3
+	run.Games[1].Failed = true
+	run.Games[1].NumGuesses = 6
+	run.Games[2].NumGuesses = 2
+	store.PutActiveRun(context.Background(), run)
+
+	rec := getRunStatus(store, "team", runID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if strings.Contains(rec.Body.String(), run.Games[0].Answer) {
+		t.Error("run status must not reveal answers")
+	}
+
+	var resp RunStatusResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+
+	if resp.NumSolved != 1 || resp.NumFailed != 1 || resp.NumUnsolved != common.NumTargetWords-2 {
+		t.Errorf("unexpected counts %+v", resp)
+	}
+	if resp.TotalGuesses != 11 {
+		t.Errorf("expected 11 total guesses, got %d", resp.TotalGuesses)
+	}
+	if resp.ExpiresInSeconds <= 0 || resp.ExpiresInSeconds > int64(storage.ActiveRunTTL.Seconds()) {
+		t.Errorf("unexpected expiry %d", resp.ExpiresInSeconds)
+	}
+	if len(resp.Games) != common.NumTargetWords || resp.Games[2].NumGuesses != 2 {
+		t.Errorf("unexpected per-game status")
+	}
+}
+
+func TestRunStatusOtherTeamForbidden(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "other")
+
+	rec := getRunStatus(store, "other", runID)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for another team's run, got %d", rec.Code)
+	}
+}
+
+func TestRunStatusUnknownRun(t *testing.T) {
+	rec := getRunStatus(storage.NewMemoryStore(), "team", uuid.NewString())
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+// expiredRunStore reports every run as expired but not yet deleted.
+type expiredRunStore struct {
+	storage.RunStore
+}
+
+func (expiredRunStore) GetActiveRun(ctx context.Context, teamID, runID string) (*storage.ActiveRunItem, error) {
+	return nil, fmt.Errorf("%w: team_id=%s, run_id=%s", storage.ErrRunExpired, teamID, runID)
+}
+
+func TestRunStatusExpiredRun(t *testing.T) {
+	rec := getRunStatus(expiredRunStore{storage.NewMemoryStore()}, "team", uuid.NewString())
+	if rec.Code != http.StatusGone {
+		t.Errorf("expected 410 for an expired run, got %d", rec.Code)
+	}
+}
+
+func getRunHistory(handler http.HandlerFunc, teamID, runID string) (*httptest.ResponseRecorder, RunHistoryResponse) {
+	req := httptest.NewRequest(http.MethodGet, "/api/runs/"+teamID+"/"+runID+"/history", nil)
+	req.SetPathValue("team_id", teamID)
+	req.SetPathValue("run_id", runID)
+
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	var resp RunHistoryResponse
+	if rec.Code == http.StatusOK {
+		json.NewDecoder(bytes.NewReader(rec.Body.Bytes())).Decode(&resp)
+	}
+	return rec, resp
+}
+
+func TestRunHistorySurvivesCompletion(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+	run, _ := store.GetActiveRun(context.Background(), "team", runID)
+
+	wrong := "xylyl"
+	if run.Games[0].Answer == wrong {
+		wrong = "crane"
+	}
+
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = run.Games[i].Answer
+	}
+	guesses[0] = wrong
+	postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: runID, Guesses: guesses})
+
+	_, active := getRunHistory(asTeam("team", RunHistoryHandler(store)), "team", runID)
+	if active.Completed || len(active.Games[0].Guesses) != 1 || active.Games[0].Guesses[0] != wrong {
+		t.Fatalf("expected one recorded guess for game 0, got %+v", active.Games[0])
+	}
+
+	for i := range guesses {
+		guesses[i] = common.DummyGuess
+	}
+	guesses[0] = run.Games[0].Answer
+	rec := postJSON(t, asTeam("team", GuessesHandler(store)), "/api/guesses", GuessesRequest{RunId: runID, Guesses: guesses})
+	var resp GuessesResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !resp.RunFinished {
+		t.Fatal("run should be finished")
+	}
+
+	_, completed := getRunHistory(asTeam("team", RunHistoryHandler(store)), "team", runID)
+	game := completed.Games[0]
+	if !completed.Completed || len(game.Guesses) != 2 || game.Hints[1] != "OOOOO" || game.Answer != "" {
+		t.Errorf("unexpected completed history for game 0: %+v", game)
+	}
+	if len(completed.Games[1].Guesses) != 1 {
+		t.Errorf("DummyGuess should not be recorded, got %+v", completed.Games[1])
+	}
+
+	_, audit := getRunHistory(AdminRunHistoryHandler(store), "team", runID)
+	if audit.Games[0].Answer != run.Games[0].Answer {
+		t.Errorf("organizers should see answers, got %+v", audit.Games[0])
+	}
+}
+
+func TestRunStatusRejectsChunkRunID(t *testing.T) {
+	store := storage.NewMemoryStore()
+	runID := startRun(t, store, "team")
+
+	rec := getRunStatus(store, "team", runID+"#games#0000")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a game chunk's run_id, got %d", rec.Code)
+	}
+}
+
+func TestRunHistoryUnknownRun(t *testing.T) {
+	rec, _ := getRunHistory(asTeam("team", RunHistoryHandler(storage.NewMemoryStore())), "team", uuid.NewString())
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
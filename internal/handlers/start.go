@@ -2,53 +2,195 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/config"
 	"wordle-tournament-backend/internal/storage"
-	"wordle-tournament-backend/internal/wordle"
+	"wordle-tournament-backend/internal/wordle/corpus"
 )
 
+// StartRequest starts a run for the team that owns the request's API key.
+// TeamID is optional and, if set, must match that team.
+//
+// Mode is storage.ModeRanked, the default, or storage.ModePractice. Practice
+// runs are never scored or counted against a quota, and only they may set
+// Seed, which fixes the answer set, Corpus, which picks the word list,
+// NumGames, which plays only that many games, and RevealAnswers, which returns
+// the answers once the run is finished.
+//
+// HardMode requires every guess to use what the earlier hints of its game
+// revealed: 'O' letters stay in place and '~' letters must be reused.
+//
+// TournamentID plays the run under a tournament's guess limit, corpus, number
+// of games, hard mode and run TTL. Ranked runs must name one: they are entered
+// in the tournament, which must be open and must not have used up the team's
+// run quota. A practice run only borrows its settings.
 type StartRequest struct {
-	TeamID string `json:"team_id"`
+	TeamID        string `json:"team_id,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	Seed          *int64 `json:"seed,omitempty"`
+	Corpus        string `json:"corpus,omitempty"`
+	NumGames      int    `json:"num_games,omitempty"`
+	HardMode      bool   `json:"hard_mode,omitempty"`
+	RevealAnswers bool   `json:"reveal_answers,omitempty"`
+	TournamentID  string `json:"tournament_id,omitempty"`
 }
 
+// StartResponse identifies the new run, its corpus and word length, its
+// number of games and whether it is in hard mode. ExpiresAt is in Unix seconds.
 type StartResponse struct {
-	RunID string `json:"run_id"`
+	RunID        string `json:"run_id"`
+	Mode         string `json:"mode"`
+	Corpus       string `json:"corpus"`
+	WordLength   int    `json:"word_length"`
+	NumGames     int    `json:"num_games"`
+	HardMode     bool   `json:"hard_mode"`
+	TournamentID string `json:"tournament_id,omitempty"`
+	ExpiresAt    int64  `json:"expires_at"`
 }
 
-func StartHandler() http.HandlerFunc {
+func StartHandler(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			handlePostStart(w, r)
+			handlePostStart(store, w, r)
 		default:
-			http.Error(w, "HTTP Method not allowed", http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 		}
 	}
 }
 
-func handlePostStart(w http.ResponseWriter, r *http.Request) {
+// handlePostStart creates a run. For a ranked tournament run, one run of the
+// team's quota is used up before the run is written, so a failed write still
+// counts.
+func handlePostStart(store storage.Store, w http.ResponseWriter, r *http.Request) {
 	var req StartRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid json body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid json body", nil)
 		return
 	}
 
-	if err := wordle.ValidateTeamId(req.TeamID); err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+	teamID, ok := resolveTeamID(w, r, req.TeamID)
+	if !ok {
 		return
 	}
 
+	if req.Mode == "" {
+		req.Mode = storage.ModeRanked
+	}
+	if req.Mode != storage.ModeRanked && req.Mode != storage.ModePractice {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("mode must be %q or %q", storage.ModeRanked, storage.ModePractice), nil)
+		return
+	}
+	practice := req.Mode == storage.ModePractice
+	if !practice && (req.Seed != nil || req.Corpus != "" || req.NumGames != 0 || req.RevealAnswers) {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "seed, corpus, num_games and reveal_answers can only be set for practice runs", nil)
+		return
+	}
+	if !practice && req.TournamentID == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "ranked runs must set tournament_id; start a practice run to play outside a tournament", nil)
+		return
+	}
+	if req.NumGames < 0 {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "num_games cannot be negative", nil)
+		return
+	}
+
+	now := time.Now()
+	settings := storage.RunSettings{
+		MaxGuesses:    config.Get().MaxGuesses,
+		Corpus:        req.Corpus,
+		NumGames:      req.NumGames,
+		HardMode:      req.HardMode,
+		Mode:          req.Mode,
+		RevealAnswers: req.RevealAnswers,
+	}
+	expiresAt := now.Add(storage.ActiveRunTTL)
+
+	var tournament *storage.TournamentItem
+	if req.TournamentID != "" {
+		if tournament, ok = loadTournament(store, w, r, req.TournamentID); !ok {
+			return
+		}
+
+		settings.TournamentID = tournament.TournamentID
+		if tournament.MaxGuesses > 0 {
+			settings.MaxGuesses = tournament.MaxGuesses
+		}
+		if settings.Corpus == "" {
+			settings.Corpus = tournament.Corpus
+		}
+		if settings.NumGames == 0 {
+			settings.NumGames = tournament.NumGames
+		}
+		settings.HardMode = settings.HardMode || tournament.HardMode
+		expiresAt = tournament.RunExpiry(now)
+		if practice {
+			expiresAt = now.Add(tournament.RunTTL())
+		}
+	}
+
+	words, ok := corpus.Get(settings.Corpus)
+	if !ok {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("unknown corpus %q, expected one of %v", settings.Corpus, corpus.Names()), nil)
+		return
+	}
+	settings.Corpus = words.Name
+
+	maxGames := storage.MaxGames(words)
+	if settings.NumGames == 0 {
+		settings.NumGames = maxGames
+	}
+	if settings.NumGames > maxGames {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("num_games cannot be more than %d for corpus %s", maxGames, words.Name), nil)
+		return
+	}
+
+	if !practice {
+		if !tournament.IsOpen(now) {
+			writeError(w, http.StatusForbidden, CodeTournamentClosed, fmt.Sprintf("tournament %s only accepts runs between %s and %s",
+				tournament.TournamentID,
+				time.Unix(tournament.StartsAt, 0).UTC().Format(time.RFC3339),
+				time.Unix(tournament.EndsAt, 0).UTC().Format(time.RFC3339)), nil)
+			return
+		}
+		if err := store.ClaimTournamentRun(r.Context(), tournament.TournamentID, teamID, tournament.MaxRunsPerTeam); err != nil {
+			writeStorageError(w, err)
+			return
+		}
+	}
+
 	runID := uuid.New().String()
 
-	if err := storage.PutDefaultActiveRun(req.TeamID, runID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	seed, err := common.GetSeed()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+		return
+	}
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	if err := storage.PutNewActiveRun(r.Context(), store, teamID, runID, seed, settings, expiresAt); err != nil {
+		writeStorageError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(StartResponse{RunID: runID})
+	json.NewEncoder(w).Encode(StartResponse{
+		RunID:        runID,
+		Mode:         settings.Mode,
+		Corpus:       settings.Corpus,
+		WordLength:   words.WordLength,
+		NumGames:     settings.NumGames,
+		HardMode:     settings.HardMode,
+		TournamentID: settings.TournamentID,
+		ExpiresAt:    expiresAt.Unix(),
+	})
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"wordle-tournament-backend/internal/auth"
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle"
+)
+
+type RegisterTeamRequest struct {
+	TeamID string `json:"team_id"`
+}
+
+// RegisterTeamResponse carries the team's API key. It is only ever returned
+// here; the server keeps just its hash.
+type RegisterTeamResponse struct {
+	TeamID string `json:"team_id"`
+	APIKey string `json:"api_key"`
+}
+
+func RegisterTeamHandler(teams storage.TeamStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handlePostTeam(teams, w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	}
+}
+
+func handlePostTeam(teams storage.TeamStore, w http.ResponseWriter, r *http.Request) {
+	var req RegisterTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid json body", nil)
+		return
+	}
+
+	if err := wordle.ValidateTeamId(req.TeamID); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	apiKey, err := auth.GenerateAPIKey()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+		return
+	}
+
+	team := storage.TeamItem{
+		TeamID:     req.TeamID,
+		APIKeyHash: auth.HashAPIKey(apiKey),
+		CreatedAt:  time.Now().Unix(),
+	}
+
+	if err := teams.CreateTeam(r.Context(), &team); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(RegisterTeamResponse{TeamID: team.TeamID, APIKey: apiKey})
+}
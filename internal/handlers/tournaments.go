@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"wordle-tournament-backend/internal/storage"
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+// maxTournamentIDLength is the longest tournament_id accepted at creation.
+const maxTournamentIDLength = 64
+
+// CreateTournamentRequest defines a tournament. StartsAt and EndsAt are Unix
+// seconds. MaxRunsPerTeam, MaxGuesses, NumGames and RunTTLSeconds may be left
+// at 0 for no limit and the server defaults, and Corpus empty for the default
+// corpus. HardMode plays every run of the tournament in hard mode.
+type CreateTournamentRequest struct {
+	TournamentID   string `json:"tournament_id"`
+	StartsAt       int64  `json:"starts_at"`
+	EndsAt         int64  `json:"ends_at"`
+	MaxRunsPerTeam int    `json:"max_runs_per_team"`
+	MaxGuesses     int    `json:"max_guesses"`
+	Corpus         string `json:"corpus,omitempty"`
+	NumGames       int    `json:"num_games"`
+	HardMode       bool   `json:"hard_mode"`
+	RunTTLSeconds  int64  `json:"run_ttl_seconds"`
+}
+
+// ListTournamentsResponse lists every tournament, ordered by start time.
+type ListTournamentsResponse struct {
+	Tournaments []storage.TournamentItem `json:"tournaments"`
+}
+
+// CreateTournamentHandler lets an admin create a tournament.
+func CreateTournamentHandler(store storage.TournamentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handlePostTournament(store, w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	}
+}
+
+func handlePostTournament(store storage.TournamentStore, w http.ResponseWriter, r *http.Request) {
+	var req CreateTournamentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid json body", nil)
+		return
+	}
+
+	if err := validateTournament(req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	tournament := storage.TournamentItem{
+		TournamentID:   req.TournamentID,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		MaxRunsPerTeam: req.MaxRunsPerTeam,
+		MaxGuesses:     req.MaxGuesses,
+		Corpus:         req.Corpus,
+		NumGames:       req.NumGames,
+		HardMode:       req.HardMode,
+		RunTTLSeconds:  req.RunTTLSeconds,
+		CreatedAt:      time.Now().Unix(),
+	}
+
+	if err := store.CreateTournament(r.Context(), &tournament); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tournament)
+}
+
+func validateTournament(req CreateTournamentRequest) error {
+	words, ok := corpus.Get(req.Corpus)
+	if !ok {
+		return fmt.Errorf("unknown corpus %q, expected one of %v", req.Corpus, corpus.Names())
+	}
+
+	switch {
+	case req.TournamentID == "":
+		return fmt.Errorf("tournament_id cannot be empty")
+	case len(req.TournamentID) > maxTournamentIDLength:
+		return fmt.Errorf("tournament_id cannot be longer than %d characters", maxTournamentIDLength)
+	case req.EndsAt <= req.StartsAt:
+		return fmt.Errorf("ends_at must be after starts_at")
+	case req.MaxRunsPerTeam < 0:
+		return fmt.Errorf("max_runs_per_team cannot be negative")
+	case req.MaxGuesses < 0 || req.MaxGuesses > storage.MaxGuessesPerGame:
+		return fmt.Errorf("max_guesses must be between 0 and %d", storage.MaxGuessesPerGame)
+	case req.NumGames < 0 || req.NumGames > storage.MaxGames(words):
+		return fmt.Errorf("num_games must be between 0 and %d", storage.MaxGames(words))
+	case req.RunTTLSeconds < 0:
+		return fmt.Errorf("run_ttl_seconds cannot be negative")
+	}
+	return nil
+}
+
+// ListTournamentsHandler lists every tournament so teams can find one to enter.
+func ListTournamentsHandler(store storage.TournamentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+
+		tournaments, err := store.ListTournaments(r.Context())
+		if err != nil {
+			writeStorageError(w, err)
+			return
+		}
+
+		sort.Slice(tournaments, func(i, j int) bool {
+			if tournaments[i].StartsAt != tournaments[j].StartsAt {
+				return tournaments[i].StartsAt < tournaments[j].StartsAt
+			}
+			return tournaments[i].TournamentID < tournaments[j].TournamentID
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ListTournamentsResponse{Tournaments: tournaments})
+	}
+}
+
+// TournamentHandler returns the tournament named by the tournament_id path value.
+func TournamentHandler(store storage.TournamentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+
+		tournament, ok := loadTournament(store, w, r, r.PathValue("tournament_id"))
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(tournament)
+	}
+}
+
+// loadTournament returns the tournament with the given ID. If it cannot be
+// loaded or does not exist it writes the error response and reports false.
+func loadTournament(store storage.TournamentStore, w http.ResponseWriter, r *http.Request, tournamentID string) (*storage.TournamentItem, bool) {
+	tournament, err := store.GetTournament(r.Context(), tournamentID)
+	if err != nil {
+		writeStorageError(w, err)
+		return nil, false
+	}
+	if tournament == nil {
+		writeError(w, http.StatusNotFound, CodeTournamentNotFound, fmt.Sprintf("tournament not found: tournament_id=%s", tournamentID), nil)
+		return nil, false
+	}
+	return tournament, true
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/storage"
+)
+
+func createTournament(t *testing.T, store storage.TournamentStore, req CreateTournamentRequest) {
+	t.Helper()
+	rec := postJSON(t, CreateTournamentHandler(store), "/admin/tournaments", req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 from /admin/tournaments, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// openTournamentID is the tournament startRun enters ranked runs in.
+const openTournamentID = "open"
+
+// ensureOpenTournament creates openTournamentID, open for the next hour, unless
+// store already has it.
+func ensureOpenTournament(t *testing.T, store storage.TournamentStore) {
+	t.Helper()
+	tournament := storage.TournamentItem{
+		TournamentID: openTournamentID,
+		StartsAt:     time.Now().Add(-time.Hour).Unix(),
+		EndsAt:       time.Now().Add(time.Hour).Unix(),
+	}
+	if err := store.CreateTournament(context.Background(), &tournament); err != nil && !errors.Is(err, storage.ErrTournamentExists) {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+}
+
+func startTournamentRun(t *testing.T, store storage.Store, teamID, tournamentID string) (int, StartResponse, ErrorResponse) {
+	t.Helper()
+	rec := postJSON(t, asTeam(teamID, StartHandler(store)), "/start", StartRequest{TournamentID: tournamentID})
+
+	var resp StartResponse
+	var errResp ErrorResponse
+	if rec.Code == http.StatusCreated {
+		json.NewDecoder(rec.Body).Decode(&resp)
+	} else {
+		json.NewDecoder(rec.Body).Decode(&errResp)
+	}
+	return rec.Code, resp, errResp
+}
+
+func TestTournamentRunUsesTournamentSettings(t *testing.T) {
+	store := storage.NewMemoryStore()
+	now := time.Now()
+	createTournament(t, store, CreateTournamentRequest{
+		TournamentID:  "spring",
+		StartsAt:      now.Add(-time.Hour).Unix(),
+		EndsAt:        now.Add(time.Minute).Unix(),
+		MaxGuesses:    3,
+		NumGames:      100,
+		HardMode:      true,
+		RunTTLSeconds: 3600,
+	})
+
+	code, resp, _ := startTournamentRun(t, store, "team", "spring")
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if resp.TournamentID != "spring" || resp.ExpiresAt > now.Add(time.Minute).Unix() {
+		t.Errorf("run should belong to the tournament and end with it, got %+v", resp)
+	}
+
+	run, _ := store.GetActiveRun(context.Background(), "team", resp.RunID)
+	if run.TournamentID != "spring" || run.MaxGuesses != 3 || len(run.Games) != 100 || !run.HardMode {
+		t.Errorf("run should carry the tournament settings, got %+v", run.RunSettings)
+	}
+}
+
+func TestTournamentRunQuota(t *testing.T) {
+	store := storage.NewMemoryStore()
+	createTournament(t, store, CreateTournamentRequest{
+		TournamentID:   "spring",
+		StartsAt:       time.Now().Add(-time.Hour).Unix(),
+		EndsAt:         time.Now().Add(time.Hour).Unix(),
+		MaxRunsPerTeam: 1,
+	})
+
+	if code, _, _ := startTournamentRun(t, store, "team", "spring"); code != http.StatusCreated {
+		t.Fatalf("expected the first run to start, got %d", code)
+	}
+	if code, _, errResp := startTournamentRun(t, store, "team", "spring"); code != http.StatusForbidden || errResp.Error.Code != CodeRunQuotaExceeded {
+		t.Errorf("expected 403 %s, got %d %s", CodeRunQuotaExceeded, code, errResp.Error.Code)
+	}
+	if code, _, _ := startTournamentRun(t, store, "other", "spring"); code != http.StatusCreated {
+		t.Errorf("quota should be per team, got %d", code)
+	}
+}
+
+func TestPracticeTournamentRunSkipsQuotaAndWindow(t *testing.T) {
+	store := storage.NewMemoryStore()
+	createTournament(t, store, CreateTournamentRequest{
+		TournamentID:   "autumn",
+		StartsAt:       time.Now().Add(time.Hour).Unix(),
+		EndsAt:         time.Now().Add(2 * time.Hour).Unix(),
+		MaxRunsPerTeam: 1,
+		MaxGuesses:     3,
+	})
+
+	for range 2 {
+		rec := postJSON(t, asTeam("team", StartHandler(store)), "/start", StartRequest{Mode: storage.ModePractice, TournamentID: "autumn"})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp StartResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		run, _ := store.GetActiveRun(context.Background(), "team", resp.RunID)
+		if !run.IsPractice() || run.MaxGuesses != 3 {
+			t.Errorf("practice run should use the tournament settings, got %+v", run.RunSettings)
+		}
+	}
+}
+
+func TestTournamentRejectsRunsOutsideWindow(t *testing.T) {
+	store := storage.NewMemoryStore()
+	createTournament(t, store, CreateTournamentRequest{
+		TournamentID: "autumn",
+		StartsAt:     time.Now().Add(time.Hour).Unix(),
+		EndsAt:       time.Now().Add(2 * time.Hour).Unix(),
+	})
+
+	code, _, errResp := startTournamentRun(t, store, "team", "autumn")
+	if code != http.StatusForbidden || errResp.Error.Code != CodeTournamentClosed {
+		t.Errorf("expected 403 %s, got %d %s", CodeTournamentClosed, code, errResp.Error.Code)
+	}
+
+	code, _, errResp = startTournamentRun(t, store, "team", "missing")
+	if code != http.StatusNotFound || errResp.Error.Code != CodeTournamentNotFound {
+		t.Errorf("expected 404 %s, got %d %s", CodeTournamentNotFound, code, errResp.Error.Code)
+	}
+}
+
+func TestCreateTournamentValidates(t *testing.T) {
+	store := storage.NewMemoryTournamentStore()
+	for _, req := range []CreateTournamentRequest{
+		{StartsAt: 1, EndsAt: 2},
+		{TournamentID: "backwards", StartsAt: 2, EndsAt: 1},
+		{TournamentID: "guesses", StartsAt: 1, EndsAt: 2, MaxGuesses: storage.MaxGuessesPerGame + 1},
+		{TournamentID: "games", StartsAt: 1, EndsAt: 2, NumGames: common.NumTargetWords + 1},
+		{TournamentID: "corpus", StartsAt: 1, EndsAt: 2, Corpus: "missing"},
+	} {
+		rec := postJSON(t, CreateTournamentHandler(store), "/admin/tournaments", req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %+v, got %d", req, rec.Code)
+		}
+	}
+}
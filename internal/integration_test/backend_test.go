@@ -6,47 +6,105 @@ package integration_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+
+	"wordle-tournament-backend/internal/auth"
 	"wordle-tournament-backend/internal/common"
 	"wordle-tournament-backend/internal/handlers"
 	"wordle-tournament-backend/internal/server"
 	"wordle-tournament-backend/internal/storage"
 )
 
-func setupIntegrationTest(t *testing.T) *httptest.Server {
-	srv := server.New()
+func setupIntegrationTest(t *testing.T) (*httptest.Server, storage.Store) {
+	store, err := storage.NewDynamoStore()
+	if err != nil {
+		t.Fatalf("Failed to create DynamoDB store: %v", err)
+	}
+	srv := server.New(store)
 	ts := httptest.NewServer(srv.Handler())
-	return ts
+	return ts, store
+}
+
+// apiClient sends requests to the test server authenticated as one team.
+type apiClient struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+// newAPIClient registers teamID with a fresh API key and returns a client
+// authenticated as that team.
+func newAPIClient(t *testing.T, ts *httptest.Server, store storage.Store, teamID string) *apiClient {
+	apiKey, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+
+	team := storage.TeamItem{TeamID: teamID, APIKeyHash: auth.HashAPIKey(apiKey)}
+	if err := store.CreateTeam(context.Background(), &team); err != nil {
+		t.Fatalf("Failed to register team: %v", err)
+	}
+
+	return &apiClient{client: &http.Client{}, baseURL: ts.URL, apiKey: apiKey}
+}
+
+// createOpenTournament creates a tournament that accepts runs for the next
+// hour and returns its ID.
+func createOpenTournament(t *testing.T, store storage.Store) string {
+	tournament := storage.TournamentItem{
+		TournamentID: "TEST_TOURNAMENT_" + uuid.NewString(),
+		StartsAt:     time.Now().Add(-time.Hour).Unix(),
+		EndsAt:       time.Now().Add(time.Hour).Unix(),
+	}
+	if err := store.CreateTournament(context.Background(), &tournament); err != nil {
+		t.Fatalf("Failed to create tournament: %v", err)
+	}
+	return tournament.TournamentID
+}
+
+func (c *apiClient) Post(path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return c.client.Do(req)
 }
 
 // TestIntegrationInstantSolve tests the complete flow of starting a run and solving all games
 // instantly with perfect guesses. It performs the following steps:
-//  1. Starts a new run by calling POST /start with a team_id, receiving a run_id
+//  1. Starts a new ranked run in an open tournament by calling POST /start with a
+//     team_id and tournament_id, receiving a run_id
 //  2. Verifies the run was created with the correct number of games (NumTargetWords),
 //     and that all games are initially unsolved with 0 guesses and valid answers
 //  3. Builds a guesses array using the actual answers from each game
 //  4. Submits all perfect guesses via POST /api/guesses
 //  5. Verifies the response contains hints for all games, and all hints are "OOOOO" (all correct)
-//  6. Verifies all games are now marked as solved with NumGuesses = 1
+//  6. Verifies the run was finalized: its score is recorded and it was removed from ActiveRuns
 func TestIntegrationInstantSolve(t *testing.T) {
-	ts := setupIntegrationTest(t)
+	ts, store := setupIntegrationTest(t)
 	defer ts.Close()
 
-	teamID := "TEST_TEAM"
-	client := &http.Client{}
+	teamID := "TEST_TEAM_" + uuid.NewString()
+	client := newAPIClient(t, ts, store, teamID)
+	tournamentID := createOpenTournament(t, store)
 
-	// Step 1: Start a new run
-	startReq := handlers.StartRequest{TeamID: teamID}
+	// Step 1: Start a new ranked run in the tournament
+	startReq := handlers.StartRequest{TeamID: teamID, TournamentID: tournamentID}
 	startBody, err := json.Marshal(startReq)
 	if err != nil {
 		t.Fatalf("Failed to marshal start request: %v", err)
 	}
 
-	startResp, err := client.Post(ts.URL+"/start", "application/json", bytes.NewBuffer(startBody))
+	startResp, err := client.Post("/start", startBody)
 	if err != nil {
 		t.Fatalf("Failed to call /start: %v", err)
 	}
@@ -69,7 +127,7 @@ func TestIntegrationInstantSolve(t *testing.T) {
 	t.Logf("Created run with ID: %s", runID)
 
 	// Step 2: Verify the run was created with correct number of games
-	activeRun, err := storage.GetActiveRun(teamID, runID)
+	activeRun, err := store.GetActiveRun(context.Background(), teamID, runID)
 	if err != nil {
 		t.Fatalf("Failed to get active run: %v", err)
 	}
@@ -109,7 +167,7 @@ func TestIntegrationInstantSolve(t *testing.T) {
 		t.Fatalf("Failed to marshal guesses request: %v", err)
 	}
 
-	guessesResp, err := client.Post(ts.URL+"/api/guesses", "application/json", bytes.NewBuffer(guessesBody))
+	guessesResp, err := client.Post("/api/guesses", guessesBody)
 	if err != nil {
 		t.Fatalf("Failed to call /api/guesses: %v", err)
 	}
@@ -135,52 +193,58 @@ func TestIntegrationInstantSolve(t *testing.T) {
 		}
 	}
 
-	// Step 6: Verify all games are now marked as solved with NumGuesses = 1
-	activeRunAfter, err := storage.GetActiveRun(teamID, runID)
-	if err != nil {
-		t.Fatalf("Failed to get active run after guesses: %v", err)
+	// Step 6: Verify the run was finalized with every game solved in one guess
+	if !guessesResponse.RunFinished {
+		t.Fatal("Run should be finished after solving every game")
 	}
 
-	if len(activeRunAfter.Games) != common.NumTargetWords {
-		t.Fatalf("Expected %d games after guesses, got %d", common.NumTargetWords, len(activeRunAfter.Games))
+	if guessesResponse.Score == nil {
+		t.Fatal("Finished run should report its score")
 	}
 
-	allSolved := true
-	for i, game := range activeRunAfter.Games {
-		if !game.Solved {
-			t.Errorf("Game %d should be solved after submitting perfect guess", i)
-			allSolved = false
-		}
-		if game.NumGuesses != 1 {
-			t.Errorf("Game %d should have 1 guess after perfect guess, got %d", i, game.NumGuesses)
-		}
+	if guessesResponse.Score.NumSolved != common.NumTargetWords {
+		t.Errorf("Expected %d solved games, got %d", common.NumTargetWords, guessesResponse.Score.NumSolved)
 	}
 
-	if !allSolved {
-		t.Fatal("Not all games were marked as solved")
+	if guessesResponse.Score.TotalGuesses != common.NumTargetWords {
+		t.Errorf("Expected %d total guesses, got %d", common.NumTargetWords, guessesResponse.Score.TotalGuesses)
+	}
+
+	if _, err := store.GetActiveRun(context.Background(), teamID, runID); err == nil {
+		t.Error("Finished run should be removed from ActiveRuns")
+	}
+
+	bestScore, err := store.GetScore(context.Background(), tournamentID, teamID)
+	if err != nil {
+		t.Fatalf("Failed to get score: %v", err)
+	}
+
+	if bestScore == nil || bestScore.NumSolved != common.NumTargetWords {
+		t.Errorf("Expected a best score with %d solved games, got %+v", common.NumTargetWords, bestScore)
 	}
 
 	t.Logf("Successfully processed all %d words", common.NumTargetWords)
 }
 
 // TestIntegrationMultipleGuessRounds tests the behavior of multiple rounds of guesses:
-//  1. Starts a new run
-//  2. Submits incorrect guesses for the first 3 games, verifying NumGuesses increments
+//  1. Starts a 3-game practice run
+//  2. Submits incorrect guesses for all 3 games, verifying NumGuesses increments
 //  3. Submits correct guesses for the first 2 games, verifying they become solved
-//  4. Submits another round with correct guess for game 3, verifying it becomes solved
-//  5. Verifies that already-solved games (games 0 and 1) don't increment NumGuesses
+//  4. Verifies that already-solved games (games 0 and 1) don't increment NumGuesses
 //     when submitting another round of guesses
+//  5. Submits a correct guess for game 2, verifying the run finishes with its score
+//  6. Verifies the finished run can no longer be played
 func TestIntegrationMultipleGuessRounds(t *testing.T) {
-	ts := setupIntegrationTest(t)
+	ts, store := setupIntegrationTest(t)
 	defer ts.Close()
 
-	teamID := "TEST_TEAM_MULTIPLE"
-	client := &http.Client{}
+	teamID := "TEST_TEAM_MULTIPLE_" + uuid.NewString()
+	client := newAPIClient(t, ts, store, teamID)
 
-	// Step 1: Start a new run
-	startReq := handlers.StartRequest{TeamID: teamID}
+	// Step 1: Start a 3-game practice run
+	startReq := handlers.StartRequest{TeamID: teamID, Mode: storage.ModePractice, NumGames: 3}
 	startBody, _ := json.Marshal(startReq)
-	startResp, err := client.Post(ts.URL+"/start", "application/json", bytes.NewBuffer(startBody))
+	startResp, err := client.Post("/start", startBody)
 	if err != nil {
 		t.Fatalf("Failed to call /start: %v", err)
 	}
@@ -191,23 +255,20 @@ func TestIntegrationMultipleGuessRounds(t *testing.T) {
 	runID := startResponse.RunID
 
 	// Get the active run to access game answers
-	activeRun, err := storage.GetActiveRun(teamID, runID)
+	activeRun, err := store.GetActiveRun(context.Background(), teamID, runID)
 	if err != nil {
 		t.Fatalf("Failed to get active run: %v", err)
 	}
 
-	if len(activeRun.Games) < 3 {
-		t.Fatalf("Need at least 3 games for this test, got %d", len(activeRun.Games))
+	if len(activeRun.Games) != 3 {
+		t.Fatalf("Expected 3 games, got %d", len(activeRun.Games))
 	}
 
-	// Step 2: Submit incorrect guesses for first 3 games
-	guesses := make([]string, common.NumTargetWords)
+	// Step 2: Submit incorrect guesses for all 3 games
+	guesses := make([]string, 3)
 	guesses[0] = "crane" // Wrong guess for game 0
 	guesses[1] = "house" // Wrong guess for game 1
 	guesses[2] = "table" // Wrong guess for game 2
-	for i := 3; i < common.NumTargetWords; i++ {
-		guesses[i] = common.DummyGuess
-	}
 
 	guessesReq := handlers.GuessesRequest{
 		TeamId:  teamID,
@@ -216,14 +277,14 @@ func TestIntegrationMultipleGuessRounds(t *testing.T) {
 	}
 
 	guessesBody, _ := json.Marshal(guessesReq)
-	guessesResp, err := client.Post(ts.URL+"/api/guesses", "application/json", bytes.NewBuffer(guessesBody))
+	guessesResp, err := client.Post("/api/guesses", guessesBody)
 	if err != nil {
 		t.Fatalf("Failed to call /api/guesses: %v", err)
 	}
 	defer guessesResp.Body.Close()
 
 	// Verify NumGuesses incremented for first 3 games
-	activeRunAfter1, err := storage.GetActiveRun(teamID, runID)
+	activeRunAfter1, err := store.GetActiveRun(context.Background(), teamID, runID)
 	if err != nil {
 		t.Fatalf("Failed to get active run: %v", err)
 	}
@@ -241,16 +302,15 @@ func TestIntegrationMultipleGuessRounds(t *testing.T) {
 	guesses[0] = activeRun.Games[0].Answer // Correct for game 0
 	guesses[1] = activeRun.Games[1].Answer // Correct for game 1
 	guesses[2] = "wrong"                   // Still wrong for game 2
-	// Games 3+ continue using DummyGuess (once DummyGuess is used, it's always used)
 
 	guessesBody, _ = json.Marshal(guessesReq)
-	guessesResp, err = client.Post(ts.URL+"/api/guesses", "application/json", bytes.NewBuffer(guessesBody))
+	guessesResp, err = client.Post("/api/guesses", guessesBody)
 	if err != nil {
 		t.Fatalf("Failed to call /api/guesses: %v", err)
 	}
 	defer guessesResp.Body.Close()
 
-	activeRunAfter2, err := storage.GetActiveRun(teamID, runID)
+	activeRunAfter2, err := store.GetActiveRun(context.Background(), teamID, runID)
 	if err != nil {
 		t.Fatalf("Failed to get active run: %v", err)
 	}
@@ -278,69 +338,74 @@ func TestIntegrationMultipleGuessRounds(t *testing.T) {
 		t.Errorf("Game 2 should have 2 guesses, got %d", activeRunAfter2.Games[2].NumGuesses)
 	}
 
-	// Step 4: Submit correct guess for game 2
-	guesses[2] = activeRun.Games[2].Answer // Correct for game 2
-	// Games 3+ continue using DummyGuess (once DummyGuess is used, it's always used)
+	// Step 4: Submit another round - already-solved games should use DummyGuess (as middleware would)
+	guesses[0] = common.DummyGuess // Middleware sends DummyGuess for already-solved game 0
+	guesses[1] = common.DummyGuess // Middleware sends DummyGuess for already-solved game 1
+	guesses[2] = "wrong"           // Still wrong for game 2
+
 	guessesBody, _ = json.Marshal(guessesReq)
-	guessesResp, err = client.Post(ts.URL+"/api/guesses", "application/json", bytes.NewBuffer(guessesBody))
+	guessesResp, err = client.Post("/api/guesses", guessesBody)
 	if err != nil {
 		t.Fatalf("Failed to call /api/guesses: %v", err)
 	}
 	defer guessesResp.Body.Close()
 
-	activeRunAfter3, err := storage.GetActiveRun(teamID, runID)
+	activeRunAfter3, err := store.GetActiveRun(context.Background(), teamID, runID)
 	if err != nil {
 		t.Fatalf("Failed to get active run: %v", err)
 	}
 
-	// Verify game 2 is now solved with NumGuesses = 3
-	if !activeRunAfter3.Games[2].Solved {
-		t.Error("Game 2 should be solved after correct guess")
+	// Verify games 0 and 1 are still solved and NumGuesses didn't increment
+	if !activeRunAfter3.Games[0].Solved {
+		t.Error("Game 0 should remain solved")
 	}
-	if activeRunAfter3.Games[2].NumGuesses != 3 {
-		t.Errorf("Game 2 should have 3 guesses, got %d", activeRunAfter3.Games[2].NumGuesses)
+	if activeRunAfter3.Games[0].NumGuesses != 2 {
+		t.Errorf("Game 0 NumGuesses should remain 2 (already solved), got %d", activeRunAfter3.Games[0].NumGuesses)
 	}
 
-	// Verify games 3+ are marked as solved when DummyGuess is used
-	// Note: DummyGuess indicates the game is already solved (by middleware/client)
-	for i := 3; i < len(activeRunAfter3.Games) && i < 10; i++ {
-		if !activeRunAfter3.Games[i].Solved {
-			t.Errorf("Game %d should be solved (DummyGuess marks it as solved)", i)
-		}
-		if activeRunAfter3.Games[i].NumGuesses != 0 {
-			t.Errorf("Game %d should have 0 guesses (DummyGuess doesn't increment), got %d", i, activeRunAfter3.Games[i].NumGuesses)
-		}
+	if !activeRunAfter3.Games[1].Solved {
+		t.Error("Game 1 should remain solved")
+	}
+	if activeRunAfter3.Games[1].NumGuesses != 2 {
+		t.Errorf("Game 1 NumGuesses should remain 2 (already solved), got %d", activeRunAfter3.Games[1].NumGuesses)
 	}
 
-	// Step 5: Submit another round - already-solved games should use DummyGuess (as middleware would)
-	guesses[0] = common.DummyGuess // Middleware sends DummyGuess for already-solved game 0
-	guesses[1] = common.DummyGuess // Middleware sends DummyGuess for already-solved game 1
-	// Games 3+ continue using DummyGuess (once DummyGuess is used, it's always used)
+	if activeRunAfter3.Games[2].NumGuesses != 3 {
+		t.Errorf("Game 2 should have 3 guesses, got %d", activeRunAfter3.Games[2].NumGuesses)
+	}
 
+	// Step 5: Submit correct guess for game 2, which finishes the run
+	guesses[2] = activeRun.Games[2].Answer // Correct for game 2
 	guessesBody, _ = json.Marshal(guessesReq)
-	guessesResp, err = client.Post(ts.URL+"/api/guesses", "application/json", bytes.NewBuffer(guessesBody))
+	guessesResp, err = client.Post("/api/guesses", guessesBody)
 	if err != nil {
 		t.Fatalf("Failed to call /api/guesses: %v", err)
 	}
 	defer guessesResp.Body.Close()
 
-	activeRunAfter4, err := storage.GetActiveRun(teamID, runID)
-	if err != nil {
-		t.Fatalf("Failed to get active run: %v", err)
+	var finalResponse handlers.GuessesResponse
+	if err := json.NewDecoder(guessesResp.Body).Decode(&finalResponse); err != nil {
+		t.Fatalf("Failed to decode guesses response: %v", err)
 	}
 
-	// Verify games 0 and 1 are still solved and NumGuesses didn't increment
-	if !activeRunAfter4.Games[0].Solved {
-		t.Error("Game 0 should remain solved")
+	if !finalResponse.RunFinished || finalResponse.Score == nil {
+		t.Fatal("Run should be finished once every game is solved")
 	}
-	if activeRunAfter4.Games[0].NumGuesses != 2 {
-		t.Errorf("Game 0 NumGuesses should remain 2 (already solved), got %d", activeRunAfter4.Games[0].NumGuesses)
+
+	// Games 0 and 1 took 2 guesses each, game 2 took 4
+	if finalResponse.Score.TotalGuesses != 8 {
+		t.Errorf("Expected 8 total guesses, got %d", finalResponse.Score.TotalGuesses)
 	}
 
-	if !activeRunAfter4.Games[1].Solved {
-		t.Error("Game 1 should remain solved")
+	// Step 6: The finished run can no longer be played
+	guessesBody, _ = json.Marshal(guessesReq)
+	guessesResp, err = client.Post("/api/guesses", guessesBody)
+	if err != nil {
+		t.Fatalf("Failed to call /api/guesses: %v", err)
 	}
-	if activeRunAfter4.Games[1].NumGuesses != 2 {
-		t.Errorf("Game 1 NumGuesses should remain 2 (already solved), got %d", activeRunAfter4.Games[1].NumGuesses)
+	defer guessesResp.Body.Close()
+
+	if guessesResp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a finished run, got %d", guessesResp.StatusCode)
 	}
 }
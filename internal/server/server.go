@@ -1,33 +1,83 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"runtime/debug"
+	"time"
+	"wordle-tournament-backend/internal/config"
 	"wordle-tournament-backend/internal/handlers"
+	"wordle-tournament-backend/internal/storage"
 )
 
 type Server struct {
-	mux *http.ServeMux
+	mux     *http.ServeMux
+	store   storage.Store
+	handler http.Handler
 }
 
-func New() *Server {
+// New returns a Server whose handlers read and write runs and scores through store.
+func New(store storage.Store) *Server {
 	s := &Server{
-		mux: http.NewServeMux(),
+		mux:   http.NewServeMux(),
+		store: store,
 	}
 
 	s.setupRoutes()
+	s.handler = withRecovery(withTimeout(s.mux, config.Get().RequestTimeout))
 	return s
 }
 
 // Handler returns the HTTP handler for the server (for testing)
 func (s *Server) Handler() http.Handler {
-	return s.mux
+	return s.handler
+}
+
+// withRecovery turns a panic in a handler into a logged 500, so one bad
+// request cannot take down the server.
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(handlers.ErrorResponse{Error: handlers.ErrorBody{Code: handlers.CodeInternal, Message: "internal server error"}})
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withTimeout bounds each request's context by timeout, so storage calls made
+// with it give up instead of waiting on a hung backend. The context is also
+// cancelled when the client disconnects.
+func withTimeout(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("/health", handlers.HealthHandler())
-	s.mux.HandleFunc("/start", handlers.StartHandler())
-	s.mux.HandleFunc("/api/guesses", handlers.GuessesHandler())
+	s.mux.HandleFunc("/start", handlers.RequireTeam(s.store, handlers.StartHandler(s.store)))
+	s.mux.HandleFunc("/api/guesses", handlers.RequireTeam(s.store, handlers.GuessesHandler(s.store)))
+	s.mux.HandleFunc("/api/leaderboard", handlers.LeaderboardHandler(s.store))
+	s.mux.HandleFunc("/api/runs/{team_id}/{run_id}", handlers.RequireTeam(s.store, handlers.RunStatusHandler(s.store)))
+	s.mux.HandleFunc("/api/runs/{team_id}/{run_id}/history", handlers.RequireTeam(s.store, handlers.RunHistoryHandler(s.store)))
+	s.mux.HandleFunc("/api/tournaments", handlers.ListTournamentsHandler(s.store))
+	s.mux.HandleFunc("/api/tournaments/{tournament_id}", handlers.TournamentHandler(s.store))
+	s.mux.HandleFunc("/admin/teams", handlers.RequireAdmin(handlers.RegisterTeamHandler(s.store)))
+	s.mux.HandleFunc("/admin/tournaments", handlers.RequireAdmin(handlers.CreateTournamentHandler(s.store)))
+	s.mux.HandleFunc("/admin/runs/{team_id}/{run_id}/history", handlers.RequireAdmin(handlers.AdminRunHistoryHandler(s.store)))
 
 	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -37,5 +87,5 @@ func (s *Server) setupRoutes() {
 
 func (s *Server) Start(port string) error {
 	addr := ":" + port
-	return http.ListenAndServe(addr, s.mux)
+	return http.ListenAndServe(addr, s.handler)
 }
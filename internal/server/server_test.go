@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wordle-tournament-backend/internal/auth"
+	"wordle-tournament-backend/internal/handlers"
+	"wordle-tournament-backend/internal/storage"
+)
+
+func TestWithRecoveryTurnsPanicInto500(t *testing.T) {
+	handler := withRecovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 after a panic, got %d", rec.Code)
+	}
+
+	var resp handlers.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Error.Code != handlers.CodeInternal {
+		t.Errorf("expected the %s error envelope, got %q (err %v)", handlers.CodeInternal, rec.Body.String(), err)
+	}
+}
+
+// hungRunStore never answers GetActiveRun before the request's context ends,
+// like a database that stopped responding.
+type hungRunStore struct {
+	storage.Store
+}
+
+func (hungRunStore) GetActiveRun(ctx context.Context, teamID, runID string) (*storage.ActiveRunItem, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("%w: GetItem: %w", storage.ErrUnavailable, ctx.Err())
+}
+
+// getRunFromHungStore requests a run from a hungRunStore through the
+// server's routes, bounded by timeout, and returns the error response.
+func getRunFromHungStore(t *testing.T, ctx context.Context, timeout time.Duration) (int, handlers.ErrorResponse) {
+	t.Helper()
+	store := hungRunStore{storage.NewMemoryStore()}
+	if err := store.CreateTeam(context.Background(), &storage.TeamItem{TeamID: "team", APIKeyHash: auth.HashAPIKey("key")}); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/runs/team/"+uuid.NewString(), nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer key")
+	rec := httptest.NewRecorder()
+	withTimeout(New(store).mux, timeout).ServeHTTP(rec, req)
+
+	var resp handlers.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestWithTimeoutReportsHungStorage(t *testing.T) {
+	code, resp := getRunFromHungStore(t, context.Background(), 10*time.Millisecond)
+	if code != http.StatusGatewayTimeout || resp.Error.Code != handlers.CodeTimeout {
+		t.Errorf("expected 504 %s, got %d %s", handlers.CodeTimeout, code, resp.Error.Code)
+	}
+}
+
+func TestDisconnectedClientReportsUnavailable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	code, resp := getRunFromHungStore(t, ctx, time.Minute)
+	if code != http.StatusServiceUnavailable || resp.Error.Code != handlers.CodeUnavailable {
+		t.Errorf("expected 503 %s, got %d %s", handlers.CodeUnavailable, code, resp.Error.Code)
+	}
+}
@@ -2,162 +2,409 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
-	"wordle-tournament-backend/internal/common"
 	"wordle-tournament-backend/internal/wordle/corpus"
 )
 
 const (
 	activeRunsTableName = "ActiveRuns"
 	ActiveRunTTL        = 10 * time.Minute
+
+	// maxRunReadAttempts is how often GetActiveRun reads a run that keeps
+	// changing under it before giving up with ErrConflict.
+	maxRunReadAttempts = 3
 )
 
-// GameState represents a single Wordle game within a run.
+// GameState represents a single Wordle game within a run. A game is finished
+// once it is either Solved or Failed; Failed means it used up the maximum
+// number of guesses without being solved.
+//
+// History holds every counted guess, in order, concatenated without
+// separators. Hints are not stored since they can be regraded from the answer.
+// In DynamoDB games are packed into a binary attribute by GameStates.
 type GameState struct {
 	Solved     bool   `json:"solved" dynamodbav:"solved"`
+	Failed     bool   `json:"failed" dynamodbav:"failed"`
 	NumGuesses int    `json:"num_guesses" dynamodbav:"num_guesses"`
 	Answer     string `json:"answer" dynamodbav:"answer"`
+	History    string `json:"history" dynamodbav:"history,omitempty"`
+}
+
+// RecordGuess appends a counted guess to the game's history.
+func (g *GameState) RecordGuess(guess string) {
+	g.NumGuesses++
+	g.History += guess
+}
+
+// Guesses returns the game's counted guesses in the order they were made.
+// Every guess has as many letters as the answer.
+func (g *GameState) Guesses() []string {
+	wordLength := utf8.RuneCountInString(g.Answer)
+	history := []rune(g.History)
+	if wordLength == 0 {
+		return nil
+	}
+
+	guesses := make([]string, 0, len(history)/wordLength)
+	for i := 0; i+wordLength <= len(history); i += wordLength {
+		guesses = append(guesses, string(history[i:i+wordLength]))
+	}
+	return guesses
+}
+
+// SubmissionRecord remembers the most recent guess submission made with an
+// idempotency key, so a retried request can be answered without regrading.
+// It is rewritten with the run every round, so it only keeps what the retried
+// request cannot supply itself: Hints are the hints of the submitted games in
+// game index order, comma-separated and empty for games that got none, and
+// Invalid maps an error code to the games whose guesses were skipped with it.
+type SubmissionRecord struct {
+	IdempotencyKey string           `dynamodbav:"idempotency_key"`
+	RequestHash    string           `dynamodbav:"request_hash"`
+	Hints          string           `dynamodbav:"hints"`
+	Invalid        map[string][]int `dynamodbav:"invalid,omitempty"`
+	RunFinished    bool             `dynamodbav:"run_finished,omitempty"`
+	Score          *ScoreItem       `dynamodbav:"score,omitempty"`
+}
+
+// Run modes. Ranked runs count towards scores and tournament quotas; practice
+// runs are for testing solvers and never do.
+const (
+	ModeRanked   = "ranked"
+	ModePractice = "practice"
+)
+
+// RunSettings are the rules a run was started with. They are fixed for the
+// life of the run. TournamentID is empty for runs outside any tournament, and
+// a MaxGuesses of 0 means the server default. Corpus names the word list the
+// run is played with; empty is corpus.DefaultName. NumGames is the number of
+// games in the run. HardMode makes every guess use what earlier hints of its
+// game revealed. An empty Mode is ModeRanked. RevealAnswers is only set on
+// practice runs and shows the answers once the run is finished.
+type RunSettings struct {
+	TournamentID  string `dynamodbav:"tournament_id,omitempty"`
+	MaxGuesses    int    `dynamodbav:"max_guesses,omitempty"`
+	Corpus        string `dynamodbav:"corpus,omitempty"`
+	NumGames      int    `dynamodbav:"num_games,omitempty"`
+	HardMode      bool   `dynamodbav:"hard_mode,omitempty"`
+	Mode          string `dynamodbav:"mode,omitempty"`
+	RevealAnswers bool   `dynamodbav:"reveal_answers,omitempty"`
+}
+
+// IsPractice reports whether the run is a practice run.
+func (s RunSettings) IsPractice() bool {
+	return s.Mode == ModePractice
+}
+
+// IsScored reports whether the run's score counts towards a tournament: it is
+// ranked and was entered in one.
+func (s RunSettings) IsScored() bool {
+	return !s.IsPractice() && s.TournamentID != ""
 }
 
 // ActiveRunItem maps (team_id, run_id) to a list of GameState entries with TTL.
+// Seed is the seed the games were generated from; it must never be shown to
+// the team, since the answers can be regenerated from it. Version counts the
+// writes to the run and is used for optimistic concurrency: a write only
+// succeeds if the stored run still has the version it was read at.
 type ActiveRunItem struct {
-	TeamID string      `dynamodbav:"team_id"`
-	RunID  string      `dynamodbav:"run_id"`
-	Games  []GameState `dynamodbav:"games"`
-	TTL    int64       `dynamodbav:"ttl"`
-}
-
-// createDefaultGames returns a slice of GameState entries, each containing
-// a unique randomly selected answer from the corpus.
-func createDefaultGameStates() []GameState {
-	possibleAnswers := corpus.GetGradingAnswerKey()
-
-	rng := rand.New(rand.NewSource(common.GetSeed()))
-	selected := make(map[string]bool)
-	games := make([]GameState, 0, common.NumTargetWords)
-
-	for len(games) < common.NumTargetWords {
-		idx := rng.Intn(len(possibleAnswers))
-		answer := possibleAnswers[idx]
-
-		if !selected[answer] {
-			selected[answer] = true
-			games = append(games, GameState{
-				Solved:     false,
-				NumGuesses: 0,
-				Answer:     answer,
-			})
+	TeamID string `dynamodbav:"team_id"`
+	RunID  string `dynamodbav:"run_id"`
+	Seed   int64  `dynamodbav:"seed"`
+	RunSettings
+	Games          GameStates        `dynamodbav:"games"`
+	TTL            int64             `dynamodbav:"ttl"`
+	Version        int64             `dynamodbav:"version"`
+	LastSubmission *SubmissionRecord `dynamodbav:"last_submission,omitempty"`
+
+	// loadedGames is the games as last read from or written to DynamoRunStore,
+	// so PutActiveRun can skip game chunks that did not change, and
+	// loadedChunkVersions the versions of the chunks holding them.
+	loadedGames         []GameState
+	loadedChunkVersions []int64
+}
+
+// Finished reports whether every game in the run is solved or failed. A run
+// without games is never finished.
+func (r *ActiveRunItem) Finished() bool {
+	if len(r.Games) == 0 {
+		return false
+	}
+	for _, game := range r.Games {
+		if !game.Solved && !game.Failed {
+			return false
 		}
 	}
+	return true
+}
+
+// GenerateGameStates returns numGames GameState entries, or one per answer if
+// c has fewer, with distinct answers drawn from c by a shuffle seeded with
+// seed. The same seed always produces the same games, so a run can be
+// regenerated from its Corpus, Seed and NumGames.
+func GenerateGameStates(c *corpus.Corpus, seed int64, numGames int) []GameState {
+	answers := slices.Clone(c.Answers())
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(answers), func(i, j int) {
+		answers[i], answers[j] = answers[j], answers[i]
+	})
+
+	games := make([]GameState, min(numGames, len(answers)))
+	for i := range games {
+		games[i] = GameState{Answer: answers[i]}
+	}
 
 	return games
 }
 
-// PutDefaultActiveRun creates a new ActiveRuns entry in DynamoDB for the given
-// team_id and run_id. The entry contains a list of GameState entries, each with
-// a unique randomly selected answer from the corpus. The item is configured with
-// a TTL that expires after ActiveRunTTL duration.
+// PutNewActiveRun creates a new run in the given store for the given team_id
+// and run_id, with settings.NumGames games, or MaxGames if it is 0, generated
+// from seed and the settings.Corpus by GenerateGameStates. The seed and
+// settings are stored with the run, which expires at expiresAt.
 //
-// Returns an error if marshaling or writing to DynamoDB fails.
-func PutDefaultActiveRun(teamID, runID string) error {
+// Returns an error if the corpus is unknown or the store fails to write the run.
+func PutNewActiveRun(ctx context.Context, store RunStore, teamID, runID string, seed int64, settings RunSettings, expiresAt time.Time) error {
+	c, ok := corpus.Get(settings.Corpus)
+	if !ok {
+		return fmt.Errorf("unknown corpus %q", settings.Corpus)
+	}
+	settings.Corpus = c.Name
+	if settings.NumGames == 0 {
+		settings.NumGames = MaxGames(c)
+	}
+
 	item := ActiveRunItem{
-		TeamID: teamID,
-		RunID:  runID,
-		Games:  createDefaultGameStates(),
-		TTL:    time.Now().Add(ActiveRunTTL).Unix(),
+		TeamID:      teamID,
+		RunID:       runID,
+		Seed:        seed,
+		RunSettings: settings,
+		Games:       GenerateGameStates(c, seed, settings.NumGames),
+		TTL:         expiresAt.Unix(),
 	}
+	item.NumGames = len(item.Games)
 
-	return PutActiveRun(&item)
+	return store.PutActiveRun(ctx, &item)
 }
 
-// GetActiveRun queries the ActiveRuns table by team_id and run_id to retrieve
-// an ActiveRunItem. If the item is found, returns a pointer to the item and nil error.
-// If the item is not found in the database, returns a nil pointer and an error.
-func GetActiveRun(teamID, runID string) (*ActiveRunItem, error) {
-	ctx := context.Background()
+// DynamoRunStore is a RunStore backed by the ActiveRuns DynamoDB table. Each
+// run is sharded into a header item and game-chunk items under the same
+// team_id partition (see run_shards.go), so no single item approaches the
+// DynamoDB item size limit and a guess round only rewrites the chunks it changed.
+type DynamoRunStore struct {
+	client *dynamodb.Client
+}
 
-	client := getDynamoClient()
+// GetActiveRun queries the ActiveRuns table for the header and game chunks of
+// the run and reassembles them into an ActiveRunItem. Returns ErrRunNotFound
+// if the header is not found, and ErrRunExpired if its TTL has passed but
+// DynamoDB has not deleted it yet, which can take hours. A runID that is not
+// a ValidRunID is never found.
+//
+// The query is not transactional, so it can observe a concurrent write halfway
+// through, with the new header next to an older chunk or the other way round.
+// assembleRun catches that from the chunk versions; the read is retried a few
+// times and then fails with ErrConflict.
+func (s *DynamoRunStore) GetActiveRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error) {
+	if !ValidRunID(runID) {
+		return nil, runNotFoundError(teamID, runID)
+	}
 
-	key, err := attributevalue.MarshalMap(map[string]string{
-		"team_id": teamID,
-		"run_id":  runID,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("marshal key: %w", err)
+	for attempt := 1; ; attempt++ {
+		run, err := s.queryActiveRun(ctx, teamID, runID)
+		if errors.Is(err, ErrConflict) && attempt < maxRunReadAttempts {
+			continue
+		}
+		return run, err
 	}
+}
 
-	result, err := client.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(activeRunsTableName),
-		Key:       key,
+// queryActiveRun reads the header and game chunks of a run in one query.
+func (s *DynamoRunStore) queryActiveRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(activeRunsTableName),
+		KeyConditionExpression: aws.String("team_id = :team_id AND begins_with(run_id, :run_id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":team_id": &types.AttributeValueMemberS{Value: teamID},
+			":run_id":  &types.AttributeValueMemberS{Value: runID},
+		},
+		ConsistentRead: aws.Bool(true),
 	})
-	if err != nil {
-		return nil, fmt.Errorf("DynamoDB GetItem operation failed: %w", err)
-	}
 
-	if result.Item == nil {
-		return nil, fmt.Errorf("run expired or not found for team_id=%s, run_id=%s", teamID, runID)
+	var header *runHeaderItem
+	var chunks []gameChunkItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, unavailableError("DynamoDB Query operation failed", err)
+		}
+
+		for _, av := range page.Items {
+			itemRunID, _ := av["run_id"].(*types.AttributeValueMemberS)
+			_, isChunk := av["chunk_index"]
+			switch {
+			case itemRunID != nil && itemRunID.Value == runID && !isChunk:
+				header = &runHeaderItem{}
+				if err := attributevalue.UnmarshalMap(av, header); err != nil {
+					return nil, fmt.Errorf("unmarshal ActiveRuns header: %w", err)
+				}
+			case itemRunID != nil && strings.HasPrefix(itemRunID.Value, chunkKeyPrefix(runID)):
+				var chunk gameChunkItem
+				if err := attributevalue.UnmarshalMap(av, &chunk); err != nil {
+					return nil, fmt.Errorf("unmarshal ActiveRuns game chunk: %w", err)
+				}
+				chunks = append(chunks, chunk)
+			}
+		}
 	}
 
-	var item ActiveRunItem
-	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
-		return nil, fmt.Errorf("unmarshal ActiveRuns item: %w", err)
+	if header == nil {
+		return nil, runNotFoundError(teamID, runID)
+	}
+	if header.TTL <= time.Now().Unix() {
+		return nil, runExpiredError(teamID, runID)
 	}
 
-	return &item, nil
+	return assembleRun(header, chunks)
 }
 
-// PutActiveRun writes the provided ActiveRunItem to the ActiveRuns table in DynamoDB.
-// It uses PutItem which will overwrite the entire item if it already exists, or create
-// it if it doesn't. Returns an error if marshaling or writing to DynamoDB fails.
-func PutActiveRun(activeRun *ActiveRunItem) error {
-	ctx := context.Background()
+// PutActiveRun writes the run header and every game chunk that differs from
+// the chunks the run was read with, in a single DynamoDB transaction. A run
+// with Version 0 is only created if no header exists yet; otherwise the write
+// is conditioned on the stored version being equal to activeRun.Version and
+// on the stored TTL not having passed. On success activeRun.Version is
+// incremented to match the stored item. Returns ErrRunExpired or ErrConflict
+// if the condition fails, or an error if the run_id is not a ValidRunID or
+// marshaling or writing to DynamoDB fails.
+func (s *DynamoRunStore) PutActiveRun(ctx context.Context, activeRun *ActiveRunItem) error {
+	if !ValidRunID(activeRun.RunID) {
+		return fmt.Errorf("invalid run_id %q", activeRun.RunID)
+	}
 
-	client := getDynamoClient()
+	chunks := changedChunks(activeRun)
+	header := newRunHeader(activeRun, chunks)
+	header.Version++
 
-	av, err := attributevalue.MarshalMap(activeRun)
+	headerAV, err := attributevalue.MarshalMap(header)
 	if err != nil {
-		return fmt.Errorf("marshal ActiveRuns item: %w", err)
+		return fmt.Errorf("marshal ActiveRuns header: %w", err)
 	}
 
-	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+	headerPut := &types.Put{
 		TableName: aws.String(activeRunsTableName),
-		Item:      av,
+		Item:      headerAV,
+	}
+	if activeRun.Version == 0 {
+		headerPut.ConditionExpression = aws.String("attribute_not_exists(run_id)")
+	} else {
+		headerPut.ConditionExpression = aws.String("version = :version AND #ttl > :now")
+		headerPut.ExpressionAttributeNames = map[string]string{"#ttl": "ttl"}
+		headerPut.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberN{Value: strconv.FormatInt(activeRun.Version, 10)},
+			":now":     &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		}
+		headerPut.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+	}
+
+	writes := []types.TransactWriteItem{{Put: headerPut}}
+	for _, chunk := range chunks {
+		chunkAV, err := attributevalue.MarshalMap(chunk)
+		if err != nil {
+			return fmt.Errorf("marshal ActiveRuns game chunk: %w", err)
+		}
+		writes = append(writes, types.TransactWriteItem{Put: &types.Put{
+			TableName: aws.String(activeRunsTableName),
+			Item:      chunkAV,
+		}})
+	}
+
+	_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: writes,
 	})
 	if err != nil {
-		return fmt.Errorf("put ActiveRuns item: %w", err)
+		var canceledErr *types.TransactionCanceledException
+		if errors.As(err, &canceledErr) && len(canceledErr.CancellationReasons) > 0 &&
+			aws.ToString(canceledErr.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			if storedRunExpired(canceledErr.CancellationReasons[0].Item) {
+				return runExpiredError(activeRun.TeamID, activeRun.RunID)
+			}
+			return fmt.Errorf("%w: team_id=%s, run_id=%s", ErrConflict, activeRun.TeamID, activeRun.RunID)
+		}
+		return unavailableError("put ActiveRuns items", err)
 	}
 
+	activeRun.Version = header.Version
+	activeRun.loadedGames = slices.Clone(activeRun.Games)
+	activeRun.loadedChunkVersions = header.ChunkVersions
 	return nil
 }
 
-// RemoveActiveRun deletes an ActiveRuns item from DynamoDB by team_id and run_id.
-// Returns an error if the key marshaling or DeleteItem operation fails.
-func RemoveActiveRun(teamID, runID string) error {
-	ctx := context.Background()
+// storedRunExpired reports whether a header returned by a failed condition
+// check has a TTL that has already passed.
+func storedRunExpired(item map[string]types.AttributeValue) bool {
+	var header runHeaderItem
+	if item == nil || attributevalue.UnmarshalMap(item, &header) != nil {
+		return false
+	}
+	return header.TTL <= time.Now().Unix()
+}
 
-	client := getDynamoClient()
+// RemoveActiveRun deletes the header and every game chunk of a run from the
+// ActiveRuns table. A runID that is not a ValidRunID matches nothing. Returns
+// an error if the Query or delete operations fail.
+func (s *DynamoRunStore) RemoveActiveRun(ctx context.Context, teamID, runID string) error {
+	if !ValidRunID(runID) {
+		return nil
+	}
 
-	key, err := attributevalue.MarshalMap(map[string]string{
-		"team_id": teamID,
-		"run_id":  runID,
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(activeRunsTableName),
+		KeyConditionExpression: aws.String("team_id = :team_id AND begins_with(run_id, :run_id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":team_id": &types.AttributeValueMemberS{Value: teamID},
+			":run_id":  &types.AttributeValueMemberS{Value: runID},
+		},
+		ProjectionExpression: aws.String("team_id, run_id"),
+		ConsistentRead:       aws.Bool(true),
 	})
-	if err != nil {
-		return fmt.Errorf("marshal key: %w", err)
+
+	var deletes []types.TransactWriteItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return unavailableError("DynamoDB Query operation failed", err)
+		}
+
+		for _, key := range page.Items {
+			deletes = append(deletes, types.TransactWriteItem{Delete: &types.Delete{
+				TableName: aws.String(activeRunsTableName),
+				Key:       key,
+			}})
+		}
 	}
 
-	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(activeRunsTableName),
-		Key:       key,
-	})
-	if err != nil {
-		return fmt.Errorf("DynamoDB DeleteItem operation failed: %w", err)
+	for len(deletes) > 0 {
+		n := min(len(deletes), maxTransactItems)
+		_, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: deletes[:n],
+		})
+		if err != nil {
+			return unavailableError("DynamoDB TransactWriteItems operation failed", err)
+		}
+		deletes = deletes[n:]
 	}
 
 	return nil
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+func answersOf(games []GameState) []string {
+	answers := make([]string, len(games))
+	for i, game := range games {
+		answers[i] = game.Answer
+	}
+	return answers
+}
+
+func TestGenerateGameStatesIsReproducible(t *testing.T) {
+	first := answersOf(GenerateGameStates(corpus.Default(), 42, common.NumTargetWords))
+	second := answersOf(GenerateGameStates(corpus.Default(), 42, common.NumTargetWords))
+
+	if !slices.Equal(first, second) {
+		t.Error("the same seed should generate the same answers")
+	}
+
+	if slices.Equal(first, answersOf(GenerateGameStates(corpus.Default(), 43, common.NumTargetWords))) {
+		t.Error("different seeds should generate different answers")
+	}
+}
+
+func TestGenerateGameStatesFollowsRunSize(t *testing.T) {
+	full := answersOf(GenerateGameStates(corpus.Default(), 42, common.NumTargetWords))
+	for _, numGames := range []int{1, 100} {
+		games := answersOf(GenerateGameStates(corpus.Default(), 42, numGames))
+		if !slices.Equal(games, full[:numGames]) {
+			t.Errorf("a %d-game run should hold the first %d answers of the full run", numGames, numGames)
+		}
+	}
+
+	if got := len(GenerateGameStates(corpus.Default(), 42, common.NumTargetWords+1)); got != common.NumTargetWords {
+		t.Errorf("runs cannot be larger than the corpus, got %d games", got)
+	}
+}
+
+func TestGenerateGameStatesAnswersAreUnique(t *testing.T) {
+	games := GenerateGameStates(corpus.Default(), 7, common.NumTargetWords)
+	if len(games) != common.NumTargetWords {
+		t.Fatalf("expected %d games, got %d", common.NumTargetWords, len(games))
+	}
+
+	seen := make(map[string]bool)
+	for i, game := range games {
+		if seen[game.Answer] {
+			t.Fatalf("answer %q repeated at game %d", game.Answer, i)
+		}
+		seen[game.Answer] = true
+	}
+}
+
+func TestFinishedNeedsGames(t *testing.T) {
+	run := &ActiveRunItem{}
+	if run.Finished() {
+		t.Error("a run without games must not be finished")
+	}
+
+	run.Games = []GameState{{Solved: true}, {Failed: true}}
+	if !run.Finished() {
+		t.Error("a run whose games are all solved or failed must be finished")
+	}
+}
+
+func TestValidRunID(t *testing.T) {
+	const runID = "0b6f5c1e-2d3a-4f4e-9a8b-7c6d5e4f3a2b"
+	if !ValidRunID(runID) {
+		t.Errorf("expected %q to be valid", runID)
+	}
+	for _, invalid := range []string{"", "run", chunkKey(runID, 0), "0B6F5C1E-2D3A-4F4E-9A8B-7C6D5E4F3A2B", "{" + runID + "}"} {
+		if ValidRunID(invalid) {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+const completedRunsTableName = "CompletedRuns"
+
+// CompletedRunStore keeps finished runs, including their guess history, after
+// they are removed from the run store. Completed runs do not expire.
+type CompletedRunStore interface {
+	// PutCompletedRun stores a finished run, overwriting any earlier copy.
+	PutCompletedRun(ctx context.Context, run *ActiveRunItem) error
+
+	// GetCompletedRun returns the finished run for the given team_id and
+	// run_id, or nil if there is none.
+	GetCompletedRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error)
+}
+
+// DynamoCompletedRunStore is a CompletedRunStore backed by the CompletedRuns
+// DynamoDB table, which has the same key schema as ActiveRuns but no TTL.
+type DynamoCompletedRunStore struct {
+	client *dynamodb.Client
+}
+
+// PutCompletedRun writes the run to the CompletedRuns table. Its TTL attribute
+// is kept for reference but the table does not expire items.
+func (s *DynamoCompletedRunStore) PutCompletedRun(ctx context.Context, run *ActiveRunItem) error {
+	av, err := attributevalue.MarshalMap(run)
+	if err != nil {
+		return fmt.Errorf("marshal CompletedRuns item: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(completedRunsTableName),
+		Item:      av,
+	})
+	if err != nil {
+		return unavailableError("put CompletedRuns item", err)
+	}
+
+	return nil
+}
+
+// GetCompletedRun reads the run from the CompletedRuns table. Returns a nil
+// pointer and nil error if it is not there.
+func (s *DynamoCompletedRunStore) GetCompletedRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error) {
+	key, err := attributevalue.MarshalMap(map[string]string{
+		"team_id": teamID,
+		"run_id":  runID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal key: %w", err)
+	}
+
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(completedRunsTableName),
+		Key:       key,
+	})
+	if err != nil {
+		return nil, unavailableError("DynamoDB GetItem operation failed", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item ActiveRunItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("unmarshal CompletedRuns item: %w", err)
+	}
+
+	return &item, nil
+}
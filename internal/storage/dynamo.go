@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -13,24 +14,33 @@ import (
 )
 
 var (
-	dynamoOnce   sync.Once
+	dynamoMu     sync.Mutex
 	dynamoClient *dynamodb.Client
 )
 
 // getDynamoClient returns the shared DynamoDB client, initializing it
-// lazily on the first call using initializeDynamo. Subsequent calls return
-// the same client instance.
-func getDynamoClient() *dynamodb.Client {
-	dynamoOnce.Do(initializeDynamo)
-	return dynamoClient
+// lazily using initializeDynamo. A failed initialization is not cached, so a
+// later call can succeed once the configuration is fixed.
+func getDynamoClient() (*dynamodb.Client, error) {
+	dynamoMu.Lock()
+	defer dynamoMu.Unlock()
+
+	if dynamoClient == nil {
+		client, err := initializeDynamo()
+		if err != nil {
+			return nil, err
+		}
+		dynamoClient = client
+	}
+	return dynamoClient, nil
 }
 
-// initializeDynamo initializes the DynamoDB client from configuration.
-// it is only ever run once.
-func initializeDynamo() {
+// initializeDynamo creates a DynamoDB client from configuration. Returns an
+// error if DYNAMODB_ENDPOINT is not set or the AWS config cannot be loaded.
+func initializeDynamo() (*dynamodb.Client, error) {
 	cfg := config.Get()
 	if cfg.DynamoDBEndpoint == "" {
-		panic("DYNAMODB_ENDPOINT environment variable must be set")
+		return nil, errors.New("DYNAMODB_ENDPOINT environment variable must be set")
 	}
 
 	region := cfg.Region
@@ -38,10 +48,29 @@ func initializeDynamo() {
 
 	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
 	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	dynamoClient = dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+	return dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
 		o.BaseEndpoint = aws.String(cfg.DynamoDBEndpoint)
-	})
+	}), nil
+}
+
+// checkDynamoTables reports whether every table the API uses exists and is
+// active, so a misconfigured deployment fails at startup instead of on the
+// first request.
+func checkDynamoTables(ctx context.Context, client *dynamodb.Client) error {
+	for _, table := range []string{
+		activeRunsTableName, completedRunsTableName, scoresTableName, teamsTableName,
+		tournamentsTableName, tournamentEntriesTableName,
+	} {
+		out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return unavailableError("DynamoDB DescribeTable "+table, err)
+		}
+		if status := out.Table.TableStatus; status != "ACTIVE" {
+			return fmt.Errorf("%w: table %s is %s", ErrUnavailable, table, status)
+		}
+	}
+	return nil
 }
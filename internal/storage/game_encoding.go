@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+// gameEncodingVersion is the first byte of every encoded GameStates value.
+const gameEncodingVersion = 1
+
+// MaxGuessesPerGame is the most guesses a game can record, since the encoding
+// stores guess counts in one byte.
+const MaxGuessesPerGame = 255
+
+const (
+	gameSolvedBit = 1 << iota
+	gameFailedBit
+)
+
+var errInvalidGameEncoding = errors.New("invalid game state encoding")
+
+// GameStates is a list of games that DynamoDB stores as a single packed binary
+// attribute instead of a list of maps. After the version byte comes the corpus
+// that words are indexed in:
+//
+//	uint8  length of the corpus name
+//	       corpus name
+//
+// and then each game is encoded as:
+//
+//	uint16 answer index into the corpus's Answers
+//	uint8  status bits (solved, failed)
+//	uint8  NumGuesses
+//	uint8  number of guesses in History
+//	uint16 WordIndex of each guess in History
+//
+// so a game costs 5 bytes plus 2 per guess. The corpus is only a dictionary
+// for the words and need not be the run's own: any corpus holding every
+// answer and guess decodes to the same games. Values stored as a list of maps
+// by older versions are still decoded.
+type GameStates []GameState
+
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+func (g GameStates) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	data, err := encodeGames(g)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AttributeValueMemberB{Value: data}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (g *GameStates) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberB:
+		games, err := decodeGames(v.Value)
+		if err != nil {
+			return err
+		}
+		*g = games
+		return nil
+	case *types.AttributeValueMemberL:
+		return attributevalue.Unmarshal(v, (*[]GameState)(g))
+	case *types.AttributeValueMemberNULL:
+		*g = nil
+		return nil
+	default:
+		return fmt.Errorf("%w: unexpected attribute type %T", errInvalidGameEncoding, av)
+	}
+}
+
+func encodeGames(games []GameState) ([]byte, error) {
+	c := dictionaryFor(games)
+	if c == nil {
+		return nil, fmt.Errorf("encode games: no corpus holds every answer and guess")
+	}
+	if len(c.Name) > math.MaxUint8 {
+		return nil, fmt.Errorf("encode games: corpus name %q is too long", c.Name)
+	}
+
+	data := make([]byte, 0, 2+len(c.Name)+len(games)*5)
+	data = append(data, gameEncodingVersion, byte(len(c.Name)))
+	data = append(data, c.Name...)
+
+	for i := range games {
+		game := &games[i]
+
+		var status byte
+		if game.Solved {
+			status |= gameSolvedBit
+		}
+		if game.Failed {
+			status |= gameFailedBit
+		}
+
+		guesses := game.Guesses()
+		if game.NumGuesses > MaxGuessesPerGame || len(guesses) > MaxGuessesPerGame {
+			return nil, fmt.Errorf("encode game: %d guesses do not fit in one byte", max(game.NumGuesses, len(guesses)))
+		}
+
+		answer, _ := c.AnswerIndex(game.Answer)
+		data = binary.BigEndian.AppendUint16(data, uint16(answer))
+		data = append(data, status, byte(game.NumGuesses), byte(len(guesses)))
+
+		for _, guess := range guesses {
+			index, _ := c.WordIndex(guess)
+			data = binary.BigEndian.AppendUint16(data, uint16(index))
+		}
+	}
+
+	return data, nil
+}
+
+// dictionaryFor returns the corpus to index the words of games in: the
+// default corpus if it holds every answer and guess, otherwise the first other
+// corpus by name that does, or nil if none does.
+func dictionaryFor(games []GameState) *corpus.Corpus {
+	for _, name := range append([]string{corpus.DefaultName}, corpus.Names()...) {
+		c, ok := corpus.Get(name)
+		if ok && holdsGames(c, games) {
+			return c
+		}
+	}
+	return nil
+}
+
+func holdsGames(c *corpus.Corpus, games []GameState) bool {
+	for i := range games {
+		if _, ok := c.AnswerIndex(games[i].Answer); !ok {
+			return false
+		}
+		for _, guess := range games[i].Guesses() {
+			if _, ok := c.WordIndex(guess); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func decodeGames(data []byte) ([]GameState, error) {
+	c, data, err := decodeGamesCorpus(data)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := c.Answers()
+	var games []GameState
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, fmt.Errorf("%w: truncated game %d", errInvalidGameEncoding, len(games))
+		}
+
+		answer := int(binary.BigEndian.Uint16(data))
+		status, numGuesses, historyLen := data[2], int(data[3]), int(data[4])
+		data = data[5:]
+
+		if answer >= len(answers) {
+			return nil, fmt.Errorf("%w: answer index %d out of range", errInvalidGameEncoding, answer)
+		}
+		if len(data) < historyLen*2 {
+			return nil, fmt.Errorf("%w: truncated history of game %d", errInvalidGameEncoding, len(games))
+		}
+
+		game := GameState{
+			Solved:     status&gameSolvedBit != 0,
+			Failed:     status&gameFailedBit != 0,
+			NumGuesses: numGuesses,
+			Answer:     answers[answer],
+		}
+
+		for k := 0; k < historyLen; k++ {
+			guess, ok := c.WordAt(int(binary.BigEndian.Uint16(data[k*2:])))
+			if !ok {
+				return nil, fmt.Errorf("%w: guess index out of range in game %d", errInvalidGameEncoding, len(games))
+			}
+			game.History += guess
+		}
+		data = data[historyLen*2:]
+
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+// decodeGamesCorpus reads the version and corpus name at the start of data and
+// returns the corpus with the rest of data.
+func decodeGamesCorpus(data []byte) (*corpus.Corpus, []byte, error) {
+	if len(data) == 0 || data[0] != gameEncodingVersion {
+		return nil, nil, fmt.Errorf("%w: unknown version", errInvalidGameEncoding)
+	}
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return nil, nil, fmt.Errorf("%w: truncated corpus name", errInvalidGameEncoding)
+	}
+
+	name := string(data[2 : 2+int(data[1])])
+	c, ok := corpus.Get(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unknown corpus %q", errInvalidGameEncoding, name)
+	}
+	return c, data[2+len(name):], nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/wordle/corpus"
+	"wordle-tournament-backend/internal/wordle/corpus/corpustest"
+)
+
+func TestGameStatesRoundTrip(t *testing.T) {
+	games := GenerateGameStates(corpus.Default(), 3, common.NumTargetWords)
+	games[0].RecordGuess("crane")
+	games[0].RecordGuess(games[0].Answer)
+	games[0].Solved = true
+	for i := 0; i < 6; i++ {
+		games[1].RecordGuess("house")
+	}
+	games[1].Failed = true
+
+	item := gameChunkItem{TeamID: "team", RunID: chunkKey("run", 0), Games: games}
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		t.Fatalf("MarshalMap: %v", err)
+	}
+
+	encoded, ok := av["games"].(*types.AttributeValueMemberB)
+	if !ok {
+		t.Fatalf("games should be stored as a binary attribute, got %T", av["games"])
+	}
+	if want := 2 + len(corpus.DefaultName) + len(games)*5 + 2*2 + 6*2; len(encoded.Value) != want {
+		t.Errorf("expected %d encoded bytes, got %d", want, len(encoded.Value))
+	}
+
+	var decoded gameChunkItem
+	if err := attributevalue.UnmarshalMap(av, &decoded); err != nil {
+		t.Fatalf("UnmarshalMap: %v", err)
+	}
+
+	if len(decoded.Games) != len(games) {
+		t.Fatalf("expected %d games, got %d", len(games), len(decoded.Games))
+	}
+	for i := range games {
+		if decoded.Games[i] != games[i] {
+			t.Fatalf("game %d differs after round trip: %+v != %+v", i, decoded.Games[i], games[i])
+		}
+	}
+}
+
+func TestGameStatesDecodesLegacyList(t *testing.T) {
+	games := []GameState{{Solved: true, NumGuesses: 2, Answer: "crane", History: "housecrane"}}
+	list, err := attributevalue.Marshal(games)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var decoded GameStates
+	if err := attributevalue.Unmarshal(list, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0] != games[0] {
+		t.Errorf("unexpected legacy decode %+v", decoded)
+	}
+}
+
+func TestGameStatesRoundTripOtherCorpus(t *testing.T) {
+	c := corpustest.Load(t, "test4", "baum haus maus", "maus")
+
+	games := GenerateGameStates(c, 1, 1)
+	games[0].RecordGuess("haus")
+	data, err := encodeGames(games)
+	if err != nil {
+		t.Fatalf("encodeGames: %v", err)
+	}
+	if string(data[2:2+data[1]]) != "test4" {
+		t.Errorf("expected the games to be indexed in test4, got %q", data[2:2+data[1]])
+	}
+
+	decoded, err := decodeGames(data)
+	if err != nil || len(decoded) != 1 || decoded[0] != games[0] {
+		t.Errorf("unexpected round trip %+v (err %v)", decoded, err)
+	}
+	if guesses := decoded[0].Guesses(); len(guesses) != 1 || guesses[0] != "haus" {
+		t.Errorf("expected 4-letter guesses, got %q", guesses)
+	}
+}
+
+func TestGameStatesRejectsUnknownWords(t *testing.T) {
+	if _, err := encodeGames([]GameState{{Answer: "zzzzz"}}); err == nil {
+		t.Error("expected an error for an answer outside the answer key")
+	}
+
+	if _, err := decodeGames([]byte{gameEncodingVersion, 0, 1}); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
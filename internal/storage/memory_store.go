@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+type runKey struct {
+	teamID string
+	runID  string
+}
+
+// MemoryRunStore is a RunStore that keeps runs in process memory. It is safe
+// for concurrent use and honors each item's TTL, so it behaves like the
+// DynamoDB-backed store for local development and tests.
+type MemoryRunStore struct {
+	mu   sync.Mutex
+	runs map[runKey]ActiveRunItem
+	now  func() time.Time
+}
+
+// NewMemoryRunStore returns an empty MemoryRunStore.
+func NewMemoryRunStore() *MemoryRunStore {
+	return &MemoryRunStore{
+		runs: make(map[runKey]ActiveRunItem),
+		now:  time.Now,
+	}
+}
+
+// GetActiveRun returns a copy of the stored run. Runs whose TTL has passed are
+// reported as expired until a later write sweeps them, like DynamoDB's lazy
+// TTL deletion.
+func (s *MemoryRunStore) GetActiveRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := runKey{teamID: teamID, runID: runID}
+	item, ok := s.runs[key]
+	if !ok {
+		return nil, runNotFoundError(teamID, runID)
+	}
+
+	if s.isExpired(item) {
+		return nil, runExpiredError(teamID, runID)
+	}
+
+	return copyActiveRun(&item), nil
+}
+
+// PutActiveRun stores a copy of the given run if the stored run still has
+// activeRun.Version and has not expired, or if there is no stored run and the
+// version is 0. On success activeRun.Version is incremented. Expired runs are
+// swept on every write.
+func (s *MemoryRunStore) PutActiveRun(ctx context.Context, activeRun *ActiveRunItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := runKey{teamID: activeRun.TeamID, runID: activeRun.RunID}
+	if stored, exists := s.runs[key]; exists && s.isExpired(stored) {
+		return runExpiredError(activeRun.TeamID, activeRun.RunID)
+	}
+
+	for key, item := range s.runs {
+		if s.isExpired(item) {
+			delete(s.runs, key)
+		}
+	}
+
+	stored, exists := s.runs[key]
+	if (exists && stored.Version != activeRun.Version) || (!exists && activeRun.Version != 0) {
+		return fmt.Errorf("%w: team_id=%s, run_id=%s", ErrConflict, activeRun.TeamID, activeRun.RunID)
+	}
+
+	activeRun.Version++
+	s.runs[key] = *copyActiveRun(activeRun)
+	return nil
+}
+
+// RemoveActiveRun deletes the run for the given team_id and run_id. Removing a
+// run that does not exist is not an error, matching DynamoDB DeleteItem.
+func (s *MemoryRunStore) RemoveActiveRun(ctx context.Context, teamID, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.runs, runKey{teamID: teamID, runID: runID})
+	return nil
+}
+
+func (s *MemoryRunStore) isExpired(item ActiveRunItem) bool {
+	return item.TTL <= s.now().Unix()
+}
+
+// copyActiveRun returns a deep copy of the run so callers can never mutate
+// stored state without going through PutActiveRun.
+func copyActiveRun(activeRun *ActiveRunItem) *ActiveRunItem {
+	cp := *activeRun
+	cp.Games = make([]GameState, len(activeRun.Games))
+	copy(cp.Games, activeRun.Games)
+	if activeRun.LastSubmission != nil {
+		record := *activeRun.LastSubmission
+		record.Invalid = make(map[string][]int, len(activeRun.LastSubmission.Invalid))
+		for code, indices := range activeRun.LastSubmission.Invalid {
+			record.Invalid[code] = slices.Clone(indices)
+		}
+		if record.Score != nil {
+			score := *record.Score
+			record.Score = &score
+		}
+		cp.LastSubmission = &record
+	}
+	return &cp
+}
+
+// MemoryScoreStore is a ScoreStore that keeps scores in process memory. It is
+// safe for concurrent use.
+type MemoryScoreStore struct {
+	mu     sync.Mutex
+	scores map[scoreKey]ScoreItem
+}
+
+// scoreKey identifies a team's best score in one tournament.
+type scoreKey struct {
+	tournamentID, teamID string
+}
+
+// NewMemoryScoreStore returns an empty MemoryScoreStore.
+func NewMemoryScoreStore() *MemoryScoreStore {
+	return &MemoryScoreStore{scores: make(map[scoreKey]ScoreItem)}
+}
+
+// GetScore returns a copy of the team's best score in the tournament, or nil
+// if it has none.
+func (s *MemoryScoreStore) GetScore(ctx context.Context, tournamentID, teamID string) (*ScoreItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.scores[scoreKey{tournamentID, teamID}]
+	if !ok {
+		return nil, nil
+	}
+	return &score, nil
+}
+
+// PutBestScore stores score if it beats the team's current score in its
+// tournament.
+func (s *MemoryScoreStore) PutBestScore(ctx context.Context, score *ScoreItem) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := scoreKey{score.TournamentID, score.TeamID}
+	if current, ok := s.scores[key]; ok && !score.Beats(current) {
+		return false, nil
+	}
+
+	s.scores[key] = *score
+	return true, nil
+}
+
+// ListScores returns a copy of every team's best score in the tournament.
+func (s *MemoryScoreStore) ListScores(ctx context.Context, tournamentID string) ([]ScoreItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var scores []ScoreItem
+	for key, score := range s.scores {
+		if key.tournamentID == tournamentID {
+			scores = append(scores, score)
+		}
+	}
+	return scores, nil
+}
+
+// MemoryTeamStore is a TeamStore that keeps teams in process memory. It is
+// safe for concurrent use.
+type MemoryTeamStore struct {
+	mu    sync.Mutex
+	teams map[string]TeamItem
+}
+
+// NewMemoryTeamStore returns an empty MemoryTeamStore.
+func NewMemoryTeamStore() *MemoryTeamStore {
+	return &MemoryTeamStore{teams: make(map[string]TeamItem)}
+}
+
+// CreateTeam registers the team, or returns ErrTeamExists.
+func (s *MemoryTeamStore) CreateTeam(ctx context.Context, team *TeamItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[team.TeamID]; ok {
+		return fmt.Errorf("%w: team_id=%s", ErrTeamExists, team.TeamID)
+	}
+
+	s.teams[team.TeamID] = *team
+	return nil
+}
+
+// GetTeamByAPIKeyHash returns a copy of the team owning the key, or nil.
+func (s *MemoryTeamStore) GetTeamByAPIKeyHash(ctx context.Context, apiKeyHash string) (*TeamItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, team := range s.teams {
+		if team.APIKeyHash == apiKeyHash {
+			return &team, nil
+		}
+	}
+	return nil, nil
+}
+
+// MemoryCompletedRunStore is a CompletedRunStore that keeps finished runs in
+// process memory. It is safe for concurrent use.
+type MemoryCompletedRunStore struct {
+	mu   sync.Mutex
+	runs map[runKey]ActiveRunItem
+}
+
+// NewMemoryCompletedRunStore returns an empty MemoryCompletedRunStore.
+func NewMemoryCompletedRunStore() *MemoryCompletedRunStore {
+	return &MemoryCompletedRunStore{runs: make(map[runKey]ActiveRunItem)}
+}
+
+// PutCompletedRun stores a copy of the finished run.
+func (s *MemoryCompletedRunStore) PutCompletedRun(ctx context.Context, run *ActiveRunItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs[runKey{teamID: run.TeamID, runID: run.RunID}] = *copyActiveRun(run)
+	return nil
+}
+
+// GetCompletedRun returns a copy of the finished run, or nil.
+func (s *MemoryCompletedRunStore) GetCompletedRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[runKey{teamID: teamID, runID: runID}]
+	if !ok {
+		return nil, nil
+	}
+	return copyActiveRun(&run), nil
+}
+
+type tournamentEntryKey struct {
+	tournamentID string
+	teamID       string
+}
+
+// MemoryTournamentStore is a TournamentStore that keeps tournaments and run
+// counts in process memory. It is safe for concurrent use.
+type MemoryTournamentStore struct {
+	mu          sync.Mutex
+	tournaments map[string]TournamentItem
+	runsStarted map[tournamentEntryKey]int
+}
+
+// NewMemoryTournamentStore returns an empty MemoryTournamentStore.
+func NewMemoryTournamentStore() *MemoryTournamentStore {
+	return &MemoryTournamentStore{
+		tournaments: make(map[string]TournamentItem),
+		runsStarted: make(map[tournamentEntryKey]int),
+	}
+}
+
+// CreateTournament stores the tournament, or returns ErrTournamentExists.
+func (s *MemoryTournamentStore) CreateTournament(ctx context.Context, tournament *TournamentItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tournaments[tournament.TournamentID]; ok {
+		return fmt.Errorf("%w: tournament_id=%s", ErrTournamentExists, tournament.TournamentID)
+	}
+
+	s.tournaments[tournament.TournamentID] = *tournament
+	return nil
+}
+
+// GetTournament returns a copy of the tournament, or nil.
+func (s *MemoryTournamentStore) GetTournament(ctx context.Context, tournamentID string) (*TournamentItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tournament, ok := s.tournaments[tournamentID]
+	if !ok {
+		return nil, nil
+	}
+	return &tournament, nil
+}
+
+// ListTournaments returns a copy of every tournament.
+func (s *MemoryTournamentStore) ListTournaments(ctx context.Context) ([]TournamentItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tournaments := make([]TournamentItem, 0, len(s.tournaments))
+	for _, tournament := range s.tournaments {
+		tournaments = append(tournaments, tournament)
+	}
+	return tournaments, nil
+}
+
+// ClaimTournamentRun counts a run for the team unless it would exceed maxRuns.
+func (s *MemoryTournamentStore) ClaimTournamentRun(ctx context.Context, tournamentID, teamID string, maxRuns int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := tournamentEntryKey{tournamentID: tournamentID, teamID: teamID}
+	if maxRuns > 0 && s.runsStarted[key] >= maxRuns {
+		return fmt.Errorf("%w: team_id=%s has started %d runs in tournament_id=%s", ErrRunQuotaExceeded, teamID, maxRuns, tournamentID)
+	}
+
+	s.runsStarted[key]++
+	return nil
+}
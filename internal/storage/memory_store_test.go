@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestRun(teamID, runID string, ttl time.Time) *ActiveRunItem {
+	return &ActiveRunItem{
+		TeamID: teamID,
+		RunID:  runID,
+		Games: []GameState{
+			{Answer: "crane"},
+			{Answer: "house"},
+		},
+		TTL: ttl.Unix(),
+	}
+}
+
+func TestMemoryStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunStore()
+	run := newTestRun("team", "run", time.Now().Add(ActiveRunTTL))
+
+	if err := store.PutActiveRun(ctx, run); err != nil {
+		t.Fatalf("PutActiveRun: %v", err)
+	}
+
+	got, err := store.GetActiveRun(ctx, "team", "run")
+	if err != nil {
+		t.Fatalf("GetActiveRun: %v", err)
+	}
+
+	if len(got.Games) != 2 || got.Games[1].Answer != "house" {
+		t.Errorf("unexpected games: %+v", got.Games)
+	}
+}
+
+func TestMemoryStoreReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunStore()
+	run := newTestRun("team", "run", time.Now().Add(ActiveRunTTL))
+	store.PutActiveRun(ctx, run)
+
+	run.Games[0].NumGuesses = 3
+	got, _ := store.GetActiveRun(ctx, "team", "run")
+	got.Games[1].Solved = true
+
+	again, _ := store.GetActiveRun(ctx, "team", "run")
+	if again.Games[0].NumGuesses != 0 || again.Games[1].Solved {
+		t.Errorf("stored run was mutated without PutActiveRun: %+v", again.Games)
+	}
+}
+
+func TestMemoryStoreHonorsTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	store.PutActiveRun(ctx, newTestRun("team", "run", now.Add(ActiveRunTTL)))
+
+	now = now.Add(ActiveRunTTL + time.Second)
+	if _, err := store.GetActiveRun(ctx, "team", "run"); !errors.Is(err, ErrRunExpired) {
+		t.Errorf("expected ErrRunExpired for an expired run, got %v", err)
+	}
+
+	store.PutActiveRun(ctx, newTestRun("team", "other", now.Add(ActiveRunTTL)))
+	if _, err := store.GetActiveRun(ctx, "team", "run"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound once the expired run is swept, got %v", err)
+	}
+}
+
+func TestMemoryStoreRejectsWritesToExpiredRun(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	run := newTestRun("team", "run", now.Add(ActiveRunTTL))
+	store.PutActiveRun(ctx, run)
+
+	now = now.Add(ActiveRunTTL + time.Second)
+	run.Games[0].RecordGuess("crane")
+	if err := store.PutActiveRun(ctx, run); !errors.Is(err, ErrRunExpired) {
+		t.Errorf("expected ErrRunExpired when writing an expired run, got %v", err)
+	}
+}
+
+func TestMemoryStoreRemove(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunStore()
+	store.PutActiveRun(ctx, newTestRun("team", "run", time.Now().Add(ActiveRunTTL)))
+
+	if err := store.RemoveActiveRun(ctx, "team", "run"); err != nil {
+		t.Fatalf("RemoveActiveRun: %v", err)
+	}
+
+	if _, err := store.GetActiveRun(ctx, "team", "run"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound for a removed run, got %v", err)
+	}
+
+	if err := store.RemoveActiveRun(ctx, "team", "missing"); err != nil {
+		t.Errorf("removing a missing run should not fail, got %v", err)
+	}
+}
+
+func TestMemoryStoreRejectsStaleWrites(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunStore()
+	store.PutActiveRun(ctx, newTestRun("team", "run", time.Now().Add(ActiveRunTTL)))
+
+	first, _ := store.GetActiveRun(ctx, "team", "run")
+	second, _ := store.GetActiveRun(ctx, "team", "run")
+
+	first.Games[0].NumGuesses++
+	if err := store.PutActiveRun(ctx, first); err != nil {
+		t.Fatalf("first write should succeed, got %v", err)
+	}
+
+	second.Games[1].NumGuesses++
+	if err := store.PutActiveRun(ctx, second); !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale write should fail with ErrConflict, got %v", err)
+	}
+
+	got, _ := store.GetActiveRun(ctx, "team", "run")
+	if got.Games[0].NumGuesses != 1 || got.Games[1].NumGuesses != 0 || got.Version != 2 {
+		t.Errorf("unexpected stored run after conflict: %+v", got)
+	}
+
+	if err := store.PutActiveRun(ctx, newTestRun("team", "run", time.Now().Add(ActiveRunTTL))); !errors.Is(err, ErrConflict) {
+		t.Errorf("creating a run that already exists should fail with ErrConflict, got %v", err)
+	}
+}
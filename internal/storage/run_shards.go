@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+const (
+	// gamesPerChunk is the number of games stored in each game-chunk item. A
+	// full 2315-game run has 24 chunks of at most about 2 KB each.
+	gamesPerChunk = 100
+
+	// maxTransactItems is the DynamoDB limit on items per transaction. A new
+	// run writes its header and every chunk in one transaction, which limits
+	// it to MaxGamesPerRun games.
+	maxTransactItems = 100
+)
+
+// MaxGamesPerRun is the most games a run can have: as many as fit in the game
+// chunks of one transaction next to the header.
+const MaxGamesPerRun = (maxTransactItems - 1) * gamesPerChunk
+
+// MaxGames returns the most games a run played with c can have: one per
+// answer, up to MaxGamesPerRun.
+func MaxGames(c *corpus.Corpus) int {
+	return min(len(c.Answers()), MaxGamesPerRun)
+}
+
+// runHeaderItem is the ActiveRuns item keyed by the run's own run_id. It holds
+// everything in an ActiveRunItem except the games. ChunkVersions is the
+// Version each game chunk was last written with, so a read that sees this
+// header next to an older copy of a chunk can tell.
+type runHeaderItem struct {
+	TeamID string `dynamodbav:"team_id"`
+	RunID  string `dynamodbav:"run_id"`
+	Seed   int64  `dynamodbav:"seed"`
+	RunSettings
+	TTL            int64             `dynamodbav:"ttl"`
+	Version        int64             `dynamodbav:"version"`
+	ChunkVersions  []int64           `dynamodbav:"chunk_versions"`
+	LastSubmission *SubmissionRecord `dynamodbav:"last_submission,omitempty"`
+}
+
+// gameChunkItem is an ActiveRuns item holding up to gamesPerChunk consecutive
+// games of a run. Its run_id is the run's run_id followed by chunkKeyPrefix and
+// the chunk's index, so a begins_with query on the run_id returns the header
+// and all chunks. Chunks carry the run's TTL so they expire with the header,
+// and the header Version they were written with.
+type gameChunkItem struct {
+	TeamID  string     `dynamodbav:"team_id"`
+	RunID   string     `dynamodbav:"run_id"`
+	TTL     int64      `dynamodbav:"ttl"`
+	Index   int        `dynamodbav:"chunk_index"`
+	Version int64      `dynamodbav:"version"`
+	Games   GameStates `dynamodbav:"games"`
+}
+
+func chunkKeyPrefix(runID string) string {
+	return runID + "#games#"
+}
+
+func chunkKey(runID string, index int) string {
+	return fmt.Sprintf("%s%04d", chunkKeyPrefix(runID), index)
+}
+
+// newRunHeader returns the header item of run written together with the
+// given changed chunks. Its NumGames is always the number of games actually
+// stored and its ChunkVersions the versions of the chunks as they will be
+// stored, which assembleRun checks the chunks against.
+func newRunHeader(run *ActiveRunItem, chunks []gameChunkItem) runHeaderItem {
+	header := runHeaderItem{
+		TeamID:         run.TeamID,
+		RunID:          run.RunID,
+		Seed:           run.Seed,
+		RunSettings:    run.RunSettings,
+		TTL:            run.TTL,
+		Version:        run.Version,
+		ChunkVersions:  make([]int64, (len(run.Games)+gamesPerChunk-1)/gamesPerChunk),
+		LastSubmission: run.LastSubmission,
+	}
+	header.NumGames = len(run.Games)
+	copy(header.ChunkVersions, run.loadedChunkVersions)
+	for _, chunk := range chunks {
+		header.ChunkVersions[chunk.Index] = chunk.Version
+	}
+	return header
+}
+
+// changedChunks returns the game chunks of run that differ from the games it
+// was loaded with, stamped with the Version that writing run creates. A run
+// that was never loaded has every chunk returned.
+func changedChunks(run *ActiveRunItem) []gameChunkItem {
+	var chunks []gameChunkItem
+	for start := 0; start < len(run.Games); start += gamesPerChunk {
+		end := min(start+gamesPerChunk, len(run.Games))
+		if len(run.loadedGames) == len(run.Games) && slices.Equal(run.Games[start:end], run.loadedGames[start:end]) {
+			continue
+		}
+
+		index := start / gamesPerChunk
+		chunks = append(chunks, gameChunkItem{
+			TeamID:  run.TeamID,
+			RunID:   chunkKey(run.RunID, index),
+			TTL:     run.TTL,
+			Index:   index,
+			Version: run.Version + 1,
+			Games:   run.Games[start:end],
+		})
+	}
+	return chunks
+}
+
+// assembleRun rebuilds an ActiveRunItem from its header and game chunks.
+// Returns ErrConflict if a chunk was not written with the version the header
+// expects, which happens when the chunks were read while a write was being
+// applied, and an error if chunks are missing or do not add up to the
+// header's number of games.
+func assembleRun(header *runHeaderItem, chunks []gameChunkItem) (*ActiveRunItem, error) {
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+
+	if len(chunks) != len(header.ChunkVersions) {
+		return nil, fmt.Errorf("run team_id=%s, run_id=%s has %d game chunks, expected %d", header.TeamID, header.RunID, len(chunks), len(header.ChunkVersions))
+	}
+
+	games := make([]GameState, 0, header.NumGames)
+	for i, chunk := range chunks {
+		if chunk.Index != i {
+			return nil, fmt.Errorf("run team_id=%s, run_id=%s is missing game chunk %d", header.TeamID, header.RunID, i)
+		}
+		if chunk.Version != header.ChunkVersions[i] {
+			return nil, fmt.Errorf("%w: team_id=%s, run_id=%s: game chunk %d has version %d, expected %d",
+				ErrConflict, header.TeamID, header.RunID, i, chunk.Version, header.ChunkVersions[i])
+		}
+		games = append(games, chunk.Games...)
+	}
+
+	if len(games) != header.NumGames {
+		return nil, fmt.Errorf("run team_id=%s, run_id=%s has %d games, expected %d", header.TeamID, header.RunID, len(games), header.NumGames)
+	}
+
+	return &ActiveRunItem{
+		TeamID:              header.TeamID,
+		RunID:               header.RunID,
+		Seed:                header.Seed,
+		RunSettings:         header.RunSettings,
+		Games:               games,
+		TTL:                 header.TTL,
+		Version:             header.Version,
+		LastSubmission:      header.LastSubmission,
+		loadedGames:         slices.Clone(games),
+		loadedChunkVersions: header.ChunkVersions,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+func TestChangedChunksOnlyReturnsModifiedChunks(t *testing.T) {
+	run := &ActiveRunItem{TeamID: "team", RunID: "run", Games: GenerateGameStates(corpus.Default(), 1, common.NumTargetWords)}
+
+	chunks := changedChunks(run)
+	wantChunks := (common.NumTargetWords + gamesPerChunk - 1) / gamesPerChunk
+	if len(chunks) != wantChunks {
+		t.Fatalf("a new run should write all %d chunks, got %d", wantChunks, len(chunks))
+	}
+	if wantChunks+1 > maxTransactItems {
+		t.Fatalf("a new run needs %d transaction items, more than the limit of %d", wantChunks+1, maxTransactItems)
+	}
+
+	header := newRunHeader(run, chunks)
+	loaded, err := assembleRun(&header, chunks)
+	if err != nil {
+		t.Fatalf("assembleRun: %v", err)
+	}
+
+	if len(changedChunks(loaded)) != 0 {
+		t.Error("an unmodified run should not write any chunks")
+	}
+
+	loaded.Games[250].RecordGuess("crane")
+	changed := changedChunks(loaded)
+	if len(changed) != 1 || changed[0].Index != 2 || changed[0].RunID != "run#games#0002" {
+		t.Errorf("expected only chunk 2 to change, got %+v", changed)
+	}
+}
+
+func TestAssembleRunRestoresOrder(t *testing.T) {
+	run := &ActiveRunItem{
+		TeamID:      "team",
+		RunID:       "run",
+		Seed:        9,
+		Version:     3,
+		RunSettings: RunSettings{TournamentID: "spring", MaxGuesses: 4, NumGames: common.NumTargetWords},
+		Games:       GenerateGameStates(corpus.Default(), 1, common.NumTargetWords),
+	}
+	chunks := changedChunks(run)
+	header := newRunHeader(run, chunks)
+	chunks[0], chunks[len(chunks)-1] = chunks[len(chunks)-1], chunks[0]
+
+	got, err := assembleRun(&header, chunks)
+	if err != nil {
+		t.Fatalf("assembleRun: %v", err)
+	}
+
+	if got.Seed != 9 || got.Version != 3 || got.RunSettings != run.RunSettings {
+		t.Errorf("header fields not restored: %+v", got)
+	}
+	for i := range run.Games {
+		if got.Games[i] != run.Games[i] {
+			t.Fatalf("game %d differs after reassembly", i)
+		}
+	}
+}
+
+func TestAssembleRunDetectsMissingChunk(t *testing.T) {
+	run := &ActiveRunItem{TeamID: "team", RunID: "run", Games: GenerateGameStates(corpus.Default(), 1, common.NumTargetWords)}
+	chunks := changedChunks(run)
+	header := newRunHeader(run, chunks)
+
+	if _, err := assembleRun(&header, append(chunks[:3:3], chunks[4:]...)); err == nil {
+		t.Error("expected an error for a missing chunk")
+	}
+}
+
+func TestAssembleRunDetectsStaleChunk(t *testing.T) {
+	run := &ActiveRunItem{TeamID: "team", RunID: "run", Games: GenerateGameStates(corpus.Default(), 1, common.NumTargetWords)}
+	chunks := changedChunks(run)
+	header := newRunHeader(run, chunks)
+	loaded, err := assembleRun(&header, chunks)
+	if err != nil {
+		t.Fatalf("assembleRun: %v", err)
+	}
+	loaded.Version = header.Version + 1
+
+	// A second write changes chunk 2. A read that sees its header next to
+	// the chunk as it was before must not assemble.
+	loaded.Games[250].RecordGuess("crane")
+	changed := changedChunks(loaded)
+	newHeader := newRunHeader(loaded, changed)
+	if newHeader.ChunkVersions[2] != 2 || newHeader.ChunkVersions[1] != 1 {
+		t.Fatalf("expected only chunk 2 to move to version 2, got %v", newHeader.ChunkVersions)
+	}
+
+	if _, err := assembleRun(&newHeader, slices.Clone(chunks)); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for a stale chunk, got %v", err)
+	}
+
+	current := slices.Clone(chunks)
+	current[2] = changed[0]
+	if _, err := assembleRun(&newHeader, current); err != nil {
+		t.Errorf("assembleRun with current chunks: %v", err)
+	}
+}
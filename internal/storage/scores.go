@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	scoresTableName = "Scores"
+
+	// maxBestScoreAttempts bounds how often PutBestScore retries when another
+	// writer updates the same team's score concurrently.
+	maxBestScoreAttempts = 3
+)
+
+// ScoreItem is the result of a finished ranked run. The Scores table, keyed by
+// (tournament_id, team_id), holds one ScoreItem per team and tournament: the
+// best run that team has finished in it. NumGames is the number of games the
+// run played. SubmittedAt is in Unix milliseconds so that ties between close
+// submissions are still ordered.
+type ScoreItem struct {
+	TournamentID string `json:"tournament_id" dynamodbav:"tournament_id"`
+	TeamID       string `json:"team_id" dynamodbav:"team_id"`
+	RunID        string `json:"run_id" dynamodbav:"run_id"`
+	NumGames     int    `json:"num_games" dynamodbav:"num_games"`
+	NumSolved    int    `json:"num_solved" dynamodbav:"num_solved"`
+	NumFailed    int    `json:"num_failed" dynamodbav:"num_failed"`
+	TotalGuesses int    `json:"total_guesses" dynamodbav:"total_guesses"`
+	SubmittedAt  int64  `json:"submitted_at" dynamodbav:"submitted_at"`
+}
+
+// NewScore computes the score of a finished run, submitted at the given time.
+func NewScore(run *ActiveRunItem, submittedAt time.Time) ScoreItem {
+	score := ScoreItem{
+		TournamentID: run.TournamentID,
+		TeamID:       run.TeamID,
+		RunID:        run.RunID,
+		NumGames:     len(run.Games),
+		SubmittedAt:  submittedAt.UnixMilli(),
+	}
+
+	for _, game := range run.Games {
+		score.TotalGuesses += game.NumGuesses
+		if game.Solved {
+			score.NumSolved++
+		} else if game.Failed {
+			score.NumFailed++
+		}
+	}
+
+	return score
+}
+
+// Beats reports whether s ranks ahead of other: more games solved first, then
+// fewer total guesses, then the earlier submission. Only scores of the same
+// tournament are compared, and every ranked run of a tournament plays the same
+// number of games.
+func (s ScoreItem) Beats(other ScoreItem) bool {
+	if s.NumSolved != other.NumSolved {
+		return s.NumSolved > other.NumSolved
+	}
+	if s.TotalGuesses != other.TotalGuesses {
+		return s.TotalGuesses < other.TotalGuesses
+	}
+	return s.SubmittedAt < other.SubmittedAt
+}
+
+// SortScores orders scores from best to worst using Beats. Scores that are
+// identical in every ranked field are ordered by team_id so the order is stable.
+func SortScores(scores []ScoreItem) {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Beats(scores[j]) {
+			return true
+		}
+		if scores[j].Beats(scores[i]) {
+			return false
+		}
+		return scores[i].TeamID < scores[j].TeamID
+	})
+}
+
+// ScoreStore persists each team's best ScoreItem in every tournament.
+type ScoreStore interface {
+	// GetScore returns the team's best score in the tournament, or nil if the
+	// team has not finished a ranked run in it yet.
+	GetScore(ctx context.Context, tournamentID, teamID string) (*ScoreItem, error)
+
+	// PutBestScore stores score if the team has no score in its tournament
+	// yet or if score beats the stored one. It reports whether score was
+	// stored.
+	PutBestScore(ctx context.Context, score *ScoreItem) (bool, error)
+
+	// ListScores returns every team's best score in the tournament in no
+	// particular order.
+	ListScores(ctx context.Context, tournamentID string) ([]ScoreItem, error)
+}
+
+// DynamoScoreStore is a ScoreStore backed by the Scores DynamoDB table.
+type DynamoScoreStore struct {
+	client *dynamodb.Client
+}
+
+// GetScore reads the team's item for the tournament from the Scores table.
+// Returns a nil pointer and nil error if the team has no score.
+func (s *DynamoScoreStore) GetScore(ctx context.Context, tournamentID, teamID string) (*ScoreItem, error) {
+	key, err := attributevalue.MarshalMap(map[string]string{"tournament_id": tournamentID, "team_id": teamID})
+	if err != nil {
+		return nil, fmt.Errorf("marshal key: %w", err)
+	}
+
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(scoresTableName),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, unavailableError("DynamoDB GetItem operation failed", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item ScoreItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("unmarshal Scores item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// PutBestScore writes score to the Scores table if it beats the team's current
+// score in the tournament. The write is conditioned on the stored run_id being unchanged since it
+// was read, so a concurrent update causes a re-read and comparison instead of
+// a lost update.
+func (s *DynamoScoreStore) PutBestScore(ctx context.Context, score *ScoreItem) (bool, error) {
+	av, err := attributevalue.MarshalMap(score)
+	if err != nil {
+		return false, fmt.Errorf("marshal Scores item: %w", err)
+	}
+
+	for attempt := 0; attempt < maxBestScoreAttempts; attempt++ {
+		current, err := s.GetScore(ctx, score.TournamentID, score.TeamID)
+		if err != nil {
+			return false, err
+		}
+
+		input := &dynamodb.PutItemInput{
+			TableName: aws.String(scoresTableName),
+			Item:      av,
+		}
+
+		if current == nil {
+			input.ConditionExpression = aws.String("attribute_not_exists(team_id)")
+		} else {
+			if !score.Beats(*current) {
+				return false, nil
+			}
+			input.ConditionExpression = aws.String("run_id = :run_id")
+			input.ExpressionAttributeValues = map[string]types.AttributeValue{
+				":run_id": &types.AttributeValueMemberS{Value: current.RunID},
+			}
+		}
+
+		_, err = s.client.PutItem(ctx, input)
+		if err == nil {
+			return true, nil
+		}
+
+		var conditionErr *types.ConditionalCheckFailedException
+		if !errors.As(err, &conditionErr) {
+			return false, unavailableError("put Scores item", err)
+		}
+	}
+
+	return false, fmt.Errorf("put Scores item: too many concurrent updates for tournament_id=%s, team_id=%s", score.TournamentID, score.TeamID)
+}
+
+// ListScores queries the tournament's partition of the Scores table, which
+// holds one item per team.
+func (s *DynamoScoreStore) ListScores(ctx context.Context, tournamentID string) ([]ScoreItem, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(scoresTableName),
+		KeyConditionExpression: aws.String("tournament_id = :tournament_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tournament_id": &types.AttributeValueMemberS{Value: tournamentID},
+		},
+	})
+
+	var scores []ScoreItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, unavailableError("DynamoDB Query operation failed", err)
+		}
+
+		var items []ScoreItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("unmarshal Scores items: %w", err)
+		}
+		scores = append(scores, items...)
+	}
+
+	return scores, nil
+}
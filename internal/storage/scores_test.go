@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewScore(t *testing.T) {
+	run := &ActiveRunItem{
+		TeamID:      "team",
+		RunID:       "run",
+		RunSettings: RunSettings{TournamentID: "spring"},
+		Games: []GameState{
+			{Solved: true, NumGuesses: 3},
+			{Solved: true, NumGuesses: 4},
+			{Failed: true, NumGuesses: 6},
+		},
+	}
+
+	score := NewScore(run, time.Unix(100, 0))
+	if score.TournamentID != "spring" || score.NumGames != 3 || score.NumSolved != 2 || score.NumFailed != 1 || score.TotalGuesses != 13 || score.SubmittedAt != 100000 {
+		t.Errorf("unexpected score %+v", score)
+	}
+}
+
+func TestScoreBeats(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b ScoreItem
+		want bool
+	}{
+		{"more solved", ScoreItem{NumSolved: 10, TotalGuesses: 50}, ScoreItem{NumSolved: 9, TotalGuesses: 30}, true},
+		{"fewer guesses", ScoreItem{NumSolved: 10, TotalGuesses: 40}, ScoreItem{NumSolved: 10, TotalGuesses: 41}, true},
+		{"earlier submission", ScoreItem{NumSolved: 10, TotalGuesses: 40, SubmittedAt: 1}, ScoreItem{NumSolved: 10, TotalGuesses: 40, SubmittedAt: 2}, true},
+		{"identical", ScoreItem{NumSolved: 10, TotalGuesses: 40}, ScoreItem{NumSolved: 10, TotalGuesses: 40}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Beats(tt.b); got != tt.want {
+				t.Errorf("Beats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryScoreStoreKeepsBest(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryScoreStore()
+
+	first := ScoreItem{TournamentID: "spring", TeamID: "team", RunID: "a", NumSolved: 10, TotalGuesses: 40}
+	if stored, _ := store.PutBestScore(ctx, &first); !stored {
+		t.Fatal("first score should be stored")
+	}
+
+	worse := ScoreItem{TournamentID: "spring", TeamID: "team", RunID: "b", NumSolved: 9, TotalGuesses: 30}
+	if stored, _ := store.PutBestScore(ctx, &worse); stored {
+		t.Error("worse score should not replace the best score")
+	}
+
+	better := ScoreItem{TournamentID: "spring", TeamID: "team", RunID: "c", NumSolved: 10, TotalGuesses: 35}
+	if stored, _ := store.PutBestScore(ctx, &better); !stored {
+		t.Error("better score should replace the best score")
+	}
+
+	best, _ := store.GetScore(ctx, "spring", "team")
+	if best.RunID != "c" {
+		t.Errorf("expected run c to be best, got %q", best.RunID)
+	}
+}
+
+func TestMemoryScoreStoreKeepsTournamentsApart(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryScoreStore()
+
+	spring := ScoreItem{TournamentID: "spring", TeamID: "team", RunID: "a", NumSolved: 10}
+	autumn := ScoreItem{TournamentID: "autumn", TeamID: "team", RunID: "b", NumSolved: 5}
+	store.PutBestScore(ctx, &spring)
+	if stored, _ := store.PutBestScore(ctx, &autumn); !stored {
+		t.Error("a score in another tournament should be stored")
+	}
+
+	if best, _ := store.GetScore(ctx, "autumn", "team"); best == nil || best.RunID != "b" {
+		t.Errorf("expected run b in autumn, got %+v", best)
+	}
+	if scores, _ := store.ListScores(ctx, "spring"); len(scores) != 1 || scores[0].RunID != "a" {
+		t.Errorf("expected only run a in spring, got %+v", scores)
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRunNotFound is returned when a run does not exist, or no longer does
+	// because it was finished or swept after expiring.
+	ErrRunNotFound = errors.New("run not found")
+
+	// ErrRunExpired is returned for a run whose TTL has passed but which has
+	// not been deleted yet.
+	ErrRunExpired = errors.New("run expired")
+
+	// ErrConflict is returned by PutActiveRun when the run was written by
+	// someone else since it was read.
+	ErrConflict = errors.New("run was modified concurrently")
+
+	// ErrUnavailable is returned when the backing database could not be
+	// reached or failed to answer. It wraps the underlying error.
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// RunStore persists ActiveRunItems keyed by (team_id, run_id). Handlers depend
+// on this interface rather than on DynamoDB directly, so the API can be served
+// from either DynamoRunStore or MemoryRunStore.
+type RunStore interface {
+	// GetActiveRun returns the run for the given team_id and run_id. It returns
+	// ErrRunNotFound if the run does not exist and ErrRunExpired if its TTL
+	// has passed.
+	GetActiveRun(ctx context.Context, teamID, runID string) (*ActiveRunItem, error)
+
+	// PutActiveRun creates the given run, or overwrites it if the stored run
+	// still has activeRun.Version. On success activeRun.Version is incremented;
+	// if the stored version differs it returns ErrConflict, and if the stored
+	// run's TTL has passed it returns ErrRunExpired.
+	PutActiveRun(ctx context.Context, activeRun *ActiveRunItem) error
+
+	// RemoveActiveRun deletes the run for the given team_id and run_id.
+	RemoveActiveRun(ctx context.Context, teamID, runID string) error
+}
+
+// Store bundles every store the API needs.
+type Store interface {
+	RunStore
+	CompletedRunStore
+	ScoreStore
+	TeamStore
+	TournamentStore
+
+	// Ready returns an error if the backing database cannot serve requests.
+	Ready(ctx context.Context) error
+}
+
+type stores struct {
+	RunStore
+	CompletedRunStore
+	ScoreStore
+	TeamStore
+	TournamentStore
+
+	ready func(ctx context.Context) error
+}
+
+func (s stores) Ready(ctx context.Context) error {
+	return s.ready(ctx)
+}
+
+// NewMemoryStore returns a Store that keeps everything in process memory.
+func NewMemoryStore() Store {
+	return stores{
+		RunStore:          NewMemoryRunStore(),
+		CompletedRunStore: NewMemoryCompletedRunStore(),
+		ScoreStore:        NewMemoryScoreStore(),
+		TeamStore:         NewMemoryTeamStore(),
+		TournamentStore:   NewMemoryTournamentStore(),
+		ready:             func(context.Context) error { return nil },
+	}
+}
+
+// NewDynamoStore returns a Store backed by DynamoDB tables. Returns an error
+// if the DynamoDB client cannot be created; whether the tables exist is only
+// checked by Ready.
+func NewDynamoStore() (Store, error) {
+	client, err := getDynamoClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return stores{
+		RunStore:          &DynamoRunStore{client: client},
+		CompletedRunStore: &DynamoCompletedRunStore{client: client},
+		ScoreStore:        &DynamoScoreStore{client: client},
+		TeamStore:         &DynamoTeamStore{client: client},
+		TournamentStore:   &DynamoTournamentStore{client: client},
+		ready: func(ctx context.Context) error {
+			return checkDynamoTables(ctx, client)
+		},
+	}, nil
+}
+
+// ValidRunID reports whether runID has the form /api/start hands out: a UUID
+// in canonical form. DynamoRunStore finds a run's items with a begins_with
+// query on its run_id, so any other ID, such as a game chunk's run_id, could
+// match items that belong to a different run.
+func ValidRunID(runID string) bool {
+	id, err := uuid.Parse(runID)
+	return err == nil && id.String() == runID
+}
+
+// runNotFoundError wraps ErrRunNotFound with the run's key.
+func runNotFoundError(teamID, runID string) error {
+	return fmt.Errorf("%w: team_id=%s, run_id=%s", ErrRunNotFound, teamID, runID)
+}
+
+// runExpiredError wraps ErrRunExpired with the run's key.
+func runExpiredError(teamID, runID string) error {
+	return fmt.Errorf("%w: team_id=%s, run_id=%s", ErrRunExpired, teamID, runID)
+}
+
+// unavailableError wraps a failed database call in ErrUnavailable, keeping
+// err in the chain so callers can still match context errors.
+func unavailableError(operation string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrUnavailable, operation, err)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	teamsTableName       = "Teams"
+	teamsAPIKeyIndexName = "api_key_hash-index"
+)
+
+// ErrTeamExists is returned by CreateTeam when the team_id is already registered.
+var ErrTeamExists = errors.New("team already exists")
+
+// TeamItem is a registered team. Only the hash of its API key is stored.
+type TeamItem struct {
+	TeamID     string `dynamodbav:"team_id"`
+	APIKeyHash string `dynamodbav:"api_key_hash"`
+	CreatedAt  int64  `dynamodbav:"created_at"`
+}
+
+// TeamStore persists registered teams.
+type TeamStore interface {
+	// CreateTeam registers a new team, or returns ErrTeamExists.
+	CreateTeam(ctx context.Context, team *TeamItem) error
+
+	// GetTeamByAPIKeyHash returns the team owning the API key with the given
+	// hash, or nil if no team does.
+	GetTeamByAPIKeyHash(ctx context.Context, apiKeyHash string) (*TeamItem, error)
+}
+
+// DynamoTeamStore is a TeamStore backed by the Teams DynamoDB table, which is
+// keyed by team_id and has a global secondary index on api_key_hash.
+type DynamoTeamStore struct {
+	client *dynamodb.Client
+}
+
+// CreateTeam writes the team to the Teams table, failing with ErrTeamExists if
+// an item with the same team_id is already present.
+func (s *DynamoTeamStore) CreateTeam(ctx context.Context, team *TeamItem) error {
+	av, err := attributevalue.MarshalMap(team)
+	if err != nil {
+		return fmt.Errorf("marshal Teams item: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(teamsTableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(team_id)"),
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return fmt.Errorf("%w: team_id=%s", ErrTeamExists, team.TeamID)
+		}
+		return unavailableError("put Teams item", err)
+	}
+
+	return nil
+}
+
+// GetTeamByAPIKeyHash queries the api_key_hash index. Global secondary indexes
+// are eventually consistent, so a key may take a moment to work after the
+// team is created.
+func (s *DynamoTeamStore) GetTeamByAPIKeyHash(ctx context.Context, apiKeyHash string) (*TeamItem, error) {
+	result, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(teamsTableName),
+		IndexName:              aws.String(teamsAPIKeyIndexName),
+		KeyConditionExpression: aws.String("api_key_hash = :hash"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hash": &types.AttributeValueMemberS{Value: apiKeyHash},
+		},
+		Limit: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, unavailableError("DynamoDB Query operation failed", err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	var item TeamItem
+	if err := attributevalue.UnmarshalMap(result.Items[0], &item); err != nil {
+		return nil, fmt.Errorf("unmarshal Teams item: %w", err)
+	}
+
+	return &item, nil
+}
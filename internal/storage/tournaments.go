@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	tournamentsTableName       = "Tournaments"
+	tournamentEntriesTableName = "TournamentEntries"
+)
+
+var (
+	// ErrTournamentExists is returned by CreateTournament when the
+	// tournament_id is already taken.
+	ErrTournamentExists = errors.New("tournament already exists")
+
+	// ErrRunQuotaExceeded is returned by ClaimTournamentRun when the team has
+	// already started as many runs as the tournament allows.
+	ErrRunQuotaExceeded = errors.New("run quota exceeded")
+)
+
+// TournamentItem is a competition that teams start runs in. Runs can only be
+// started between StartsAt and EndsAt, both in Unix seconds, and a run never
+// outlives EndsAt. MaxRunsPerTeam limits how many runs each team may start;
+// MaxGuesses, Corpus, NumGames and RunTTLSeconds override the server defaults
+// for its runs. Zero or empty means no limit or the default. HardMode plays
+// every run of the tournament in hard mode.
+type TournamentItem struct {
+	TournamentID   string `json:"tournament_id" dynamodbav:"tournament_id"`
+	StartsAt       int64  `json:"starts_at" dynamodbav:"starts_at"`
+	EndsAt         int64  `json:"ends_at" dynamodbav:"ends_at"`
+	MaxRunsPerTeam int    `json:"max_runs_per_team" dynamodbav:"max_runs_per_team"`
+	MaxGuesses     int    `json:"max_guesses" dynamodbav:"max_guesses"`
+	Corpus         string `json:"corpus,omitempty" dynamodbav:"corpus,omitempty"`
+	NumGames       int    `json:"num_games" dynamodbav:"num_games"`
+	HardMode       bool   `json:"hard_mode" dynamodbav:"hard_mode"`
+	RunTTLSeconds  int64  `json:"run_ttl_seconds" dynamodbav:"run_ttl_seconds"`
+	CreatedAt      int64  `json:"created_at" dynamodbav:"created_at"`
+}
+
+// IsOpen reports whether runs can be started in the tournament at now.
+func (t *TournamentItem) IsOpen(now time.Time) bool {
+	return now.Unix() >= t.StartsAt && now.Unix() < t.EndsAt
+}
+
+// RunTTL returns how long the tournament's runs live: RunTTLSeconds, or
+// ActiveRunTTL if unset.
+func (t *TournamentItem) RunTTL() time.Duration {
+	if t.RunTTLSeconds > 0 {
+		return time.Duration(t.RunTTLSeconds) * time.Second
+	}
+	return ActiveRunTTL
+}
+
+// RunExpiry returns when a run started at now expires: after RunTTL, but no
+// later than the end of the tournament.
+func (t *TournamentItem) RunExpiry(now time.Time) time.Time {
+	expiry := now.Add(t.RunTTL())
+	if end := time.Unix(t.EndsAt, 0); expiry.After(end) {
+		return end
+	}
+	return expiry
+}
+
+// TournamentStore persists tournaments and how many runs each team has
+// started in them.
+type TournamentStore interface {
+	// CreateTournament stores a new tournament, or returns ErrTournamentExists.
+	CreateTournament(ctx context.Context, tournament *TournamentItem) error
+
+	// GetTournament returns the tournament with the given ID, or nil if there
+	// is none.
+	GetTournament(ctx context.Context, tournamentID string) (*TournamentItem, error)
+
+	// ListTournaments returns every tournament in no particular order.
+	ListTournaments(ctx context.Context) ([]TournamentItem, error)
+
+	// ClaimTournamentRun counts one more run started by the team in the
+	// tournament. If the team has already started maxRuns runs it returns
+	// ErrRunQuotaExceeded and counts nothing. A maxRuns of 0 means no limit.
+	ClaimTournamentRun(ctx context.Context, tournamentID, teamID string, maxRuns int) error
+}
+
+// DynamoTournamentStore is a TournamentStore backed by the Tournaments table,
+// keyed by tournament_id, and the TournamentEntries table, keyed by
+// (tournament_id, team_id), which counts the runs each team has started.
+type DynamoTournamentStore struct {
+	client *dynamodb.Client
+}
+
+// CreateTournament writes the tournament to the Tournaments table, failing
+// with ErrTournamentExists if an item with the same tournament_id is present.
+func (s *DynamoTournamentStore) CreateTournament(ctx context.Context, tournament *TournamentItem) error {
+	av, err := attributevalue.MarshalMap(tournament)
+	if err != nil {
+		return fmt.Errorf("marshal Tournaments item: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(tournamentsTableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(tournament_id)"),
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return fmt.Errorf("%w: tournament_id=%s", ErrTournamentExists, tournament.TournamentID)
+		}
+		return unavailableError("put Tournaments item", err)
+	}
+
+	return nil
+}
+
+// GetTournament reads the tournament from the Tournaments table. Returns a nil
+// pointer and nil error if there is no such tournament.
+func (s *DynamoTournamentStore) GetTournament(ctx context.Context, tournamentID string) (*TournamentItem, error) {
+	key, err := attributevalue.MarshalMap(map[string]string{"tournament_id": tournamentID})
+	if err != nil {
+		return nil, fmt.Errorf("marshal key: %w", err)
+	}
+
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(tournamentsTableName),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, unavailableError("DynamoDB GetItem operation failed", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item TournamentItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("unmarshal Tournaments item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// ListTournaments scans the whole Tournaments table, which only ever holds a
+// handful of items.
+func (s *DynamoTournamentStore) ListTournaments(ctx context.Context) ([]TournamentItem, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(tournamentsTableName),
+	})
+
+	var tournaments []TournamentItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, unavailableError("DynamoDB Scan operation failed", err)
+		}
+
+		var items []TournamentItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("unmarshal Tournaments items: %w", err)
+		}
+		tournaments = append(tournaments, items...)
+	}
+
+	return tournaments, nil
+}
+
+// ClaimTournamentRun atomically increments the team's runs_started counter in
+// the TournamentEntries table, conditioned on it being below maxRuns.
+func (s *DynamoTournamentStore) ClaimTournamentRun(ctx context.Context, tournamentID, teamID string, maxRuns int) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tournamentEntriesTableName),
+		Key: map[string]types.AttributeValue{
+			"tournament_id": &types.AttributeValueMemberS{Value: tournamentID},
+			"team_id":       &types.AttributeValueMemberS{Value: teamID},
+		},
+		UpdateExpression: aws.String("ADD runs_started :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+	if maxRuns > 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(runs_started) OR runs_started < :max")
+		input.ExpressionAttributeValues[":max"] = &types.AttributeValueMemberN{Value: strconv.Itoa(maxRuns)}
+	}
+
+	_, err := s.client.UpdateItem(ctx, input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return fmt.Errorf("%w: team_id=%s has started %d runs in tournament_id=%s", ErrRunQuotaExceeded, teamID, maxRuns, tournamentID)
+		}
+		return unavailableError("update TournamentEntries item", err)
+	}
+
+	return nil
+}
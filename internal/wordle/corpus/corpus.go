@@ -1,55 +1,283 @@
 package corpus
 
 import (
-	_ "embed"
+	"embed"
+	"fmt"
+	"io/fs"
 	"log"
+	"math"
+	"os"
+	"path"
+	"sort"
 	"strings"
 	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"wordle-tournament-backend/internal/wordle/corpus/internal/testhook"
+)
+
+// DefaultName is the corpus used by runs that do not name one: 5-letter
+// English words.
+const DefaultName = "en5"
+
+// Every corpus is a directory named after it holding these two files: the
+// words that may be guessed and the possible answers, separated by whitespace.
+const (
+	wordsFile   = "corpus.txt"
+	answersFile = "possible_answers.txt"
 )
 
+// maxWords is the most words or answers a corpus may have, since stored games
+// refer to them by a 16-bit index.
+const maxWords = math.MaxUint16 + 1
+
+//go:embed lists
+var embedded embed.FS
+
 type wordSet map[string]struct{}
 
-//go:embed corpus.txt
-var corpusData string
+// Corpus is a named word list. Every word, and every answer, is WordLength
+// letters long, and every answer is also a valid word.
+type Corpus struct {
+	Name       string
+	WordLength int
 
-//go:embed possible_answers.txt
-var answersData string
+	words       wordSet
+	wordList    []string
+	wordIndex   map[string]int
+	answers     []string
+	answerIndex map[string]int
+}
 
 var (
-	corpus          wordSet
-	possibleAnswers []string
-	once            sync.Once
+	corpora  map[string]*Corpus
+	mu       sync.RWMutex
+	once     sync.Once
+	embedErr error
 )
 
-func GetCorpus() wordSet {
-	once.Do(initializeCorpus)
-	return corpus
+// LoadEmbedded loads the corpora embedded in the binary unless they are
+// already loaded, and returns an error if any of them is malformed. Get,
+// Names and LoadDir load them on first use; main calls it first so that a
+// malformed list stops the server at startup.
+func LoadEmbedded() error {
+	once.Do(func() {
+		corpora, embedErr = loadEmbedded()
+	})
+	return embedErr
+}
+
+// Default returns the DefaultName corpus, or nil if the embedded corpora
+// could not be loaded.
+func Default() *Corpus {
+	c, _ := Get(DefaultName)
+	return c
+}
+
+// Get returns the corpus with the given name. An empty name is DefaultName.
+func Get(name string) (*Corpus, bool) {
+	if err := LoadEmbedded(); err != nil {
+		return nil, false
+	}
+	if name == "" {
+		name = DefaultName
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := corpora[name]
+	return c, ok
 }
 
-func GetGradingAnswerKey() []string {
-	once.Do(initializeCorpus)
-	return possibleAnswers
+// Names returns the names of every loaded corpus in sorted order.
+func Names() []string {
+	LoadEmbedded()
+
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(corpora))
+	for name := range corpora {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
-func IsValidWord(word string) bool {
-	_, exists := GetCorpus()[word]
+// LoadDir loads every corpus in dir, laid out like the embedded lists: one
+// subdirectory per corpus with a corpus.txt and a possible_answers.txt.
+// Returns an error, and loads nothing, if a list is malformed or a corpus
+// with the same name is already loaded.
+func LoadDir(dir string) error {
+	if err := LoadEmbedded(); err != nil {
+		return err
+	}
+
+	loaded, err := loadFS(os.DirFS(dir))
+	if err != nil {
+		return fmt.Errorf("load corpora from %s: %w", dir, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for name := range loaded {
+		if _, exists := corpora[name]; exists {
+			return fmt.Errorf("load corpora from %s: corpus %q is already loaded", dir, name)
+		}
+	}
+	for name, c := range loaded {
+		corpora[name] = c
+	}
+	return nil
+}
+
+func init() {
+	testhook.Unload = unload
+}
+
+// unload removes a corpus added by LoadDir. Runs played with it can no longer
+// be graded, so only corpustest may call it, through testhook.
+func unload(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(corpora, name)
+}
+
+// IsValidWord reports whether word may be guessed.
+func (c *Corpus) IsValidWord(word string) bool {
+	_, exists := c.words[word]
 	return exists
 }
 
-func initializeCorpus() {
-	corpus = loadToSet(corpusData)
-	possibleAnswers = loadToSlice(answersData)
-	log.Printf("Loaded %d words from corpus and %d possible answers", len(corpus), len(possibleAnswers))
+// NumWords returns the number of distinct words that may be guessed.
+func (c *Corpus) NumWords() int {
+	return len(c.words)
+}
+
+// Answers returns the possible answers in file order. The slice is shared
+// and must not be modified.
+func (c *Corpus) Answers() []string {
+	return c.answers
+}
+
+// WordIndex returns the position of word in the corpus file. Indices are
+// stable as long as corpus.txt is only appended to, so they can be stored.
+func (c *Corpus) WordIndex(word string) (int, bool) {
+	index, ok := c.wordIndex[word]
+	return index, ok
+}
+
+// WordAt returns the corpus word at the given WordIndex.
+func (c *Corpus) WordAt(index int) (string, bool) {
+	if index < 0 || index >= len(c.wordList) {
+		return "", false
+	}
+	return c.wordList[index], true
+}
+
+// AnswerIndex returns the position of answer in Answers.
+func (c *Corpus) AnswerIndex(answer string) (int, bool) {
+	index, ok := c.answerIndex[answer]
+	return index, ok
+}
+
+func loadEmbedded() (map[string]*Corpus, error) {
+	lists, err := fs.Sub(embedded, "lists")
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := loadFS(lists)
+	if err != nil {
+		return nil, fmt.Errorf("load embedded corpora: %w", err)
+	}
+	return loaded, nil
+}
+
+// loadFS loads a corpus from every directory at the root of fsys.
+func loadFS(fsys fs.FS) (map[string]*Corpus, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]*Corpus)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		words, err := fs.ReadFile(fsys, path.Join(name, wordsFile))
+		if err != nil {
+			return nil, err
+		}
+		answers, err := fs.ReadFile(fsys, path.Join(name, answersFile))
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := newCorpus(name, string(words), string(answers))
+		if err != nil {
+			return nil, err
+		}
+		loaded[name] = c
+	}
+
+	return loaded, nil
 }
 
-func loadToSet(data string) wordSet {
-	ws := make(wordSet)
-	for _, word := range strings.Fields(strings.ReplaceAll(data, "\n", " ")) {
-		ws[word] = struct{}{}
+// newCorpus builds a corpus from whitespace-separated word and answer lists.
+// The word length is taken from the first word.
+func newCorpus(name, wordsData, answersData string) (*Corpus, error) {
+	wordList := strings.Fields(wordsData)
+	answers := strings.Fields(answersData)
+	if len(wordList) == 0 || len(answers) == 0 {
+		return nil, fmt.Errorf("corpus %q needs at least one word and one answer", name)
 	}
-	return ws
+	if len(wordList) > maxWords {
+		return nil, fmt.Errorf("corpus %q has %d words, at most %d are supported", name, len(wordList), maxWords)
+	}
+
+	c := &Corpus{
+		Name:        name,
+		WordLength:  utf8.RuneCountInString(wordList[0]),
+		words:       make(wordSet, len(wordList)),
+		wordList:    wordList,
+		wordIndex:   indexSlice(wordList),
+		answers:     answers,
+		answerIndex: indexSlice(answers),
+	}
+
+	for _, word := range wordList {
+		if utf8.RuneCountInString(word) != c.WordLength {
+			return nil, fmt.Errorf("corpus %q: word %q is not %d letters", name, word, c.WordLength)
+		}
+		// Guesses are NFKC-normalized and lowercased before they are looked
+		// up, so a word in any other form could never be guessed.
+		if word != strings.ToLower(norm.NFKC.String(word)) {
+			return nil, fmt.Errorf("corpus %q: word %q is not lowercase NFKC", name, word)
+		}
+		c.words[word] = struct{}{}
+	}
+	for _, answer := range answers {
+		if !c.IsValidWord(answer) {
+			return nil, fmt.Errorf("corpus %q: answer %q is not in %s", name, answer, wordsFile)
+		}
+	}
+	if len(c.answerIndex) != len(answers) {
+		return nil, fmt.Errorf("corpus %q: %s has duplicate answers", name, answersFile)
+	}
+
+	log.Printf("Loaded corpus %s: %d words of %d letters and %d possible answers", name, len(c.words), c.WordLength, len(answers))
+	return c, nil
 }
 
-func loadToSlice(data string) []string {
-	return strings.Fields(strings.ReplaceAll(data, "\n", " "))
+func indexSlice(words []string) map[string]int {
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+		index[word] = i
+	}
+	return index
 }
@@ -1,29 +1,31 @@
 package corpus
 
-import (
-	"testing"
-
-	"wordle-tournament-backend/internal/common"
-)
+import "testing"
 
 func TestCorpusLoads(t *testing.T) {
-	corpus := GetCorpus()
+	corpus := Default()
+	if corpus == nil || corpus.Name != DefaultName {
+		t.Fatalf("expected the %s corpus to be loaded, got %v", DefaultName, Names())
+	}
 
-	if len(corpus) == 0 {
-		t.Error("Corpus should not be empty")
+	if corpus.NumWords() <= 1000 {
+		t.Errorf("Corpus should have many words, got %d", corpus.NumWords())
 	}
 
-	if len(corpus) <= 1000 {
-		t.Errorf("Corpus should have many words, got %d", len(corpus))
+	if c, ok := Get(""); !ok || c != corpus {
+		t.Error("an empty name should be the default corpus")
 	}
 }
 
 func TestAllWordsCorrectLength(t *testing.T) {
-	corpus := GetCorpus()
+	corpus := Default()
+	if corpus.WordLength != 5 {
+		t.Fatalf("expected 5-letter words, got %d", corpus.WordLength)
+	}
 
-	for word := range corpus {
-		if len(word) != common.WordLength {
-			t.Errorf("Word '%s' is not %d letters", word, common.WordLength)
+	for word := range corpus.words {
+		if len(word) != corpus.WordLength {
+			t.Errorf("Word '%s' is not %d letters", word, corpus.WordLength)
 		}
 	}
 }
@@ -41,7 +43,7 @@ func TestIsValidWord(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.word, func(t *testing.T) {
-			result := IsValidWord(tt.word)
+			result := Default().IsValidWord(tt.word)
 			if result != tt.valid {
 				t.Errorf("IsValidWord(%q) = %v, want %v", tt.word, result, tt.valid)
 			}
@@ -51,11 +53,64 @@ func TestIsValidWord(t *testing.T) {
 
 func TestCaseSensitive(t *testing.T) {
 	// Assuming corpus is lowercase
-	if !IsValidWord("crane") {
+	if !Default().IsValidWord("crane") {
 		t.Error("Expected 'crane' to be valid")
 	}
 
-	if IsValidWord("CRANE") {
+	if Default().IsValidWord("CRANE") {
 		t.Error("Expected 'CRANE' to be invalid (case sensitive)")
 	}
 }
+
+func TestWordIndexRoundTrip(t *testing.T) {
+	corpus := Default()
+	index, ok := corpus.WordIndex("crane")
+	if !ok {
+		t.Fatal("expected 'crane' to have an index")
+	}
+
+	word, ok := corpus.WordAt(index)
+	if !ok || word != "crane" {
+		t.Errorf("WordAt(%d) = %q, want %q", index, word, "crane")
+	}
+
+	if _, ok := corpus.WordIndex("zzzzz"); ok {
+		t.Error("expected 'zzzzz' to have no index")
+	}
+
+	if _, ok := corpus.WordAt(corpus.NumWords()); ok {
+		t.Error("expected an index past the end to be invalid")
+	}
+}
+
+func TestAnswerIndex(t *testing.T) {
+	answers := Default().Answers()
+	for _, i := range []int{0, len(answers) / 2, len(answers) - 1} {
+		index, ok := Default().AnswerIndex(answers[i])
+		if !ok || index != i {
+			t.Errorf("AnswerIndex(%q) = %d, %v; want %d", answers[i], index, ok, i)
+		}
+	}
+}
+
+func TestNewCorpusRejectsBadLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		words   string
+		answers string
+	}{
+		{"empty", "", ""},
+		{"mixed lengths", "crane cranes", "crane"},
+		{"uppercase", "Crane house", "house"},
+		{"answer not a word", "crane house", "slate"},
+		{"duplicate answers", "crane house", "crane crane"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newCorpus(tt.name, tt.words, tt.answers); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
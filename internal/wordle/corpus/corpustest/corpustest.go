@@ -0,0 +1,42 @@
+// Package corpustest loads small corpora for tests.
+package corpustest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wordle-tournament-backend/internal/wordle/corpus"
+	"wordle-tournament-backend/internal/wordle/corpus/internal/testhook"
+)
+
+// WriteDir writes a corpus named name, from whitespace-separated words and
+// answers, into a new temporary directory laid out for corpus.LoadDir and
+// returns the directory.
+func WriteDir(t testing.TB, name, words, answers string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name, "corpus.txt"), []byte(words), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name, "possible_answers.txt"), []byte(answers), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// Load loads a corpus named name from whitespace-separated words and answers,
+// and unloads it again when the test finishes.
+func Load(t testing.TB, name, words, answers string) *corpus.Corpus {
+	t.Helper()
+	if err := corpus.LoadDir(WriteDir(t, name, words, answers)); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	t.Cleanup(func() { testhook.Unload(name) })
+
+	c, _ := corpus.Get(name)
+	return c
+}
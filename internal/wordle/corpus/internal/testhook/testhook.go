@@ -0,0 +1,8 @@
+// Package testhook gives corpustest access to corpus internals that no
+// production code may use. Being internal to package corpus, it can only be
+// imported by corpus and its subpackages.
+package testhook
+
+// Unload removes a corpus loaded by corpus.LoadDir, so that tests can load
+// the same name again. It is set by package corpus.
+var Unload func(name string)
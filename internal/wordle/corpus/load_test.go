@@ -0,0 +1,24 @@
+package corpus_test
+
+import (
+	"testing"
+
+	"wordle-tournament-backend/internal/wordle/corpus"
+	"wordle-tournament-backend/internal/wordle/corpus/corpustest"
+)
+
+func TestLoadDir(t *testing.T) {
+	corpustest.Load(t, "de4", "baum haus müde", "haus müde")
+
+	c, ok := corpus.Get("de4")
+	if !ok {
+		t.Fatalf("expected de4 to be loaded, got %v", corpus.Names())
+	}
+	if c.WordLength != 4 || !c.IsValidWord("müde") || len(c.Answers()) != 2 {
+		t.Errorf("unexpected corpus %+v", c)
+	}
+
+	if err := corpus.LoadDir(corpustest.WriteDir(t, "de4", "baum haus", "haus")); err == nil {
+		t.Error("expected an error when loading a corpus name twice")
+	}
+}
@@ -1,45 +1,55 @@
 package wordle
 
 import (
+	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"wordle-tournament-backend/internal/common"
 )
 
 // GradeGuesses takes two lists of guesses and answers and returns an array of hints.
-// Each hint is a 5-character string where:
+// Each hint has one character per letter of the answer, where:
 // - 'O' indicates a correct letter in the correct position
 // - '~' indicates a correct letter in the wrong position
 // - 'X' indicates a letter not in the answer
-func GradeGuesses(guesses, answers []string) []string {
-
-	// TODO: Remove panic before deploying to production
+//
+// Guesses and answers are compared after NormalizeGuess, so case and
+// surrounding whitespace do not affect the hint. Returns an error if the lists
+// differ in length or a guess and its answer are not the same number of letters.
+func GradeGuesses(guesses, answers []string) ([]string, error) {
 	if len(guesses) != len(answers) {
-		panic("guesses and answers must be the same length")
+		return nil, fmt.Errorf("got %d guesses for %d answers", len(guesses), len(answers))
 	}
 
 	hints := make([]string, len(guesses))
 	for i := 0; i < len(guesses); i++ {
+		answer := NormalizeGuess(answers[i])
 		if guesses[i] == common.DummyGuess {
-			hints[i] = strings.Repeat("O", common.WordLength)
-		} else {
-			hints[i] = gradeGuessLogical(guesses[i], answers[i])
+			hints[i] = strings.Repeat("O", utf8.RuneCountInString(answer))
+			continue
+		}
+
+		guess := NormalizeGuess(guesses[i])
+		if answer == "" || utf8.RuneCountInString(guess) != utf8.RuneCountInString(answer) {
+			return nil, fmt.Errorf("%w: cannot grade %q against %q", ErrInvalidWordLength, guesses[i], answers[i])
 		}
+		hints[i] = gradeGuessLogical(guess, answer)
 	}
 
-	return hints
+	return hints, nil
 }
 
 // Grade a single guess and answer mirroring the rust algorithm
 func gradeGuessLogical(guess, answer string) string {
-	hint := []rune("XXXXX")
-	remainingChars := []rune(answer)
-
 	guessRunes := []rune(guess)
 	answerRunes := []rune(answer)
 
+	hint := []rune(strings.Repeat("X", len(answerRunes)))
+	remainingChars := []rune(answer)
+
 	// Mark correctly placed characters
-	for i := 0; i < common.WordLength; i++ {
+	for i := range answerRunes {
 		if guessRunes[i] == answerRunes[i] {
 			hint[i] = 'O'
 			tryRemoveFirst(&remainingChars, guessRunes[i])
@@ -47,7 +57,7 @@ func gradeGuessLogical(guess, answer string) string {
 	}
 
 	// Mark misplaced characters
-	for i := 0; i < common.WordLength; i++ {
+	for i := range answerRunes {
 		if hint[i] == 'X' {
 			if tryRemoveFirst(&remainingChars, guessRunes[i]) {
 				hint[i] = '~'
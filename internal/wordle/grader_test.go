@@ -2,10 +2,21 @@ package wordle
 
 import (
 	"testing"
+
+	"wordle-tournament-backend/internal/common"
 )
 
+func mustGrade(t *testing.T, guesses, answers []string) []string {
+	t.Helper()
+	hints, err := GradeGuesses(guesses, answers)
+	if err != nil {
+		t.Fatalf("GradeGuesses: %v", err)
+	}
+	return hints
+}
+
 func TestAllAbsent(t *testing.T) {
-	result := GradeGuesses([]string{"crane"}, []string{"built"})
+	result := mustGrade(t, []string{"crane"}, []string{"built"})
 	expected := "XXXXX"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -13,7 +24,7 @@ func TestAllAbsent(t *testing.T) {
 }
 
 func TestAllCorrect(t *testing.T) {
-	result := GradeGuesses([]string{"crane"}, []string{"crane"})
+	result := mustGrade(t, []string{"crane"}, []string{"crane"})
 	expected := "OOOOO"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -21,7 +32,7 @@ func TestAllCorrect(t *testing.T) {
 }
 
 func TestDuplicateInTargetCausesPresent(t *testing.T) {
-	result := GradeGuesses([]string{"roost"}, []string{"robot"})
+	result := mustGrade(t, []string{"roost"}, []string{"robot"})
 	expected := "OO~XO"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -29,7 +40,7 @@ func TestDuplicateInTargetCausesPresent(t *testing.T) {
 }
 
 func TestGuessHasMoreDuplicatesThanTarget(t *testing.T) {
-	result := GradeGuesses([]string{"allee"}, []string{"apple"})
+	result := mustGrade(t, []string{"allee"}, []string{"apple"})
 	expected := "O~XXO"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -37,7 +48,7 @@ func TestGuessHasMoreDuplicatesThanTarget(t *testing.T) {
 }
 
 func TestNoMatches(t *testing.T) {
-	result := GradeGuesses([]string{"crane"}, []string{"yummy"})
+	result := mustGrade(t, []string{"crane"}, []string{"yummy"})
 	expected := "XXXXX"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -45,7 +56,7 @@ func TestNoMatches(t *testing.T) {
 }
 
 func TestMixedDuplicatesAndCorrect(t *testing.T) {
-	result := GradeGuesses([]string{"ABBEY"}, []string{"BANAL"})
+	result := mustGrade(t, []string{"ABBEY"}, []string{"BANAL"})
 	expected := "~~XXX"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -53,7 +64,7 @@ func TestMixedDuplicatesAndCorrect(t *testing.T) {
 }
 
 func TestDuplicateCorrectAndPresentSameLetter(t *testing.T) {
-	result := GradeGuesses([]string{"array"}, []string{"alarm"})
+	result := mustGrade(t, []string{"array"}, []string{"alarm"})
 	expected := "O~X~X"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
@@ -61,9 +72,32 @@ func TestDuplicateCorrectAndPresentSameLetter(t *testing.T) {
 }
 
 func TestPresentDoesNotStealFromCorrect(t *testing.T) {
-	result := GradeGuesses([]string{"babee"}, []string{"aback"})
+	result := mustGrade(t, []string{"babee"}, []string{"aback"})
 	expected := "~~XXX"
 	if result[0] != expected {
 		t.Errorf("expected %q, got %q", expected, result[0])
 	}
 }
+
+func TestMixedCaseGradesLikeLowercase(t *testing.T) {
+	result := mustGrade(t, []string{" Crane", "BABEE"}, []string{"crane", "aback"})
+	if result[0] != "OOOOO" || result[1] != "~~XXX" {
+		t.Errorf("expected mixed case to grade like lowercase, got %q", result)
+	}
+}
+
+func TestGradeGuessesRejectsMismatchedInput(t *testing.T) {
+	if _, err := GradeGuesses([]string{"crane", "slate"}, []string{"crane"}); err == nil {
+		t.Error("expected an error when guesses and answers differ in length")
+	}
+	if _, err := GradeGuesses([]string{"cranes"}, []string{"crane"}); err == nil {
+		t.Error("expected an error for a guess of the wrong length")
+	}
+}
+
+func TestGradesOtherWordLengths(t *testing.T) {
+	result := mustGrade(t, []string{"haus", "planet", common.DummyGuess}, []string{"maus", "plenty", "müde"})
+	if result[0] != "XOOO" || result[1] != "OOXO~~" || result[2] != "OOOO" {
+		t.Errorf("unexpected hints %q", result)
+	}
+}
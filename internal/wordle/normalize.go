@@ -0,0 +1,33 @@
+package wordle
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+
+	"wordle-tournament-backend/internal/common"
+)
+
+// NormalizeGuess puts a guess into the form the corpus uses: surrounding
+// whitespace is trimmed, compatibility characters such as full-width letters
+// are folded by NFKC, and letters are lowercased. DummyGuess is left as is.
+func NormalizeGuess(guess string) string {
+	if guess == common.DummyGuess {
+		return guess
+	}
+	return strings.ToLower(norm.NFKC.String(strings.TrimSpace(guess)))
+}
+
+// NormalizeGuesses normalizes every guess of a dense submission in place.
+func NormalizeGuesses(guesses []string) {
+	for i, guess := range guesses {
+		guesses[i] = NormalizeGuess(guess)
+	}
+}
+
+// NormalizeGuessMap normalizes every guess of a sparse submission in place.
+func NormalizeGuessMap(guesses map[int]string) {
+	for index, guess := range guesses {
+		guesses[index] = NormalizeGuess(guess)
+	}
+}
@@ -0,0 +1,25 @@
+package wordle
+
+import (
+	"testing"
+
+	"wordle-tournament-backend/internal/common"
+)
+
+func TestNormalizeGuess(t *testing.T) {
+	tests := []struct {
+		guess, want string
+	}{
+		{"crane", "crane"},
+		{"CRANE", "crane"},
+		{"  Crane\n", "crane"},
+		{"ｃｒａｎｅ", "crane"},
+		{common.DummyGuess, common.DummyGuess},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeGuess(tt.guess); got != tt.want {
+			t.Errorf("NormalizeGuess(%q) = %q, want %q", tt.guess, got, tt.want)
+		}
+	}
+}
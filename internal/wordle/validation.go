@@ -3,6 +3,9 @@ package wordle
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"wordle-tournament-backend/internal/common"
 	"wordle-tournament-backend/internal/wordle/corpus"
@@ -10,44 +13,213 @@ import (
 
 var (
 	ErrInvalidGuessLength = errors.New("invalid number of guesses")
-	ErrInvalidWordLength  = errors.New("word must be exactly 5 characters")
+	ErrInvalidWordLength  = errors.New("word has the wrong number of letters")
 	ErrInvalidTeamId      = errors.New("invalid team_id")
+	ErrInvalidGameIndex   = errors.New("invalid game index")
+	ErrWordNotInCorpus    = errors.New("word not in corpus")
+	ErrHardModeViolation  = errors.New("hard mode violation")
 )
 
-// TODO: Implement actual team validation logic.
+// GuessError reports which guess of a submission failed validation. Index is
+// the game index the guess was made for. It wraps the sentinel error saying
+// what was wrong with the guess.
+type GuessError struct {
+	Index int
+	Guess string
+	Err   error
+}
+
+func (e *GuessError) Error() string {
+	return fmt.Sprintf("game %d: %v: %q", e.Index, e.Err, e.Guess)
+}
+
+func (e *GuessError) Unwrap() error {
+	return e.Err
+}
+
+// GuessErrors is every invalid guess of a submission, in game index order.
+type GuessErrors []*GuessError
+
+func (e GuessErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, guessErr := range e {
+		messages[i] = guessErr.Error()
+	}
+	return fmt.Sprintf("%d invalid guesses: %s", len(e), strings.Join(messages, "; "))
+}
+
+func (e GuessErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, guessErr := range e {
+		errs[i] = guessErr
+	}
+	return errs
+}
+
+// Indices returns the game indices of the invalid guesses.
+func (e GuessErrors) Indices() map[int]bool {
+	indices := make(map[int]bool, len(e))
+	for _, guessErr := range e {
+		indices[guessErr.Index] = true
+	}
+	return indices
+}
+
+// MaxTeamIdLength is the longest team_id accepted at registration.
+const MaxTeamIdLength = 64
+
+// ValidateTeamId checks the format of a team_id being registered. Whether a
+// request may act as a team is decided by its API key, not by this check.
 func ValidateTeamId(teamid string) error {
-	// Placeholder: always returns nil (valid)
-	// In production, this would check against a database or auth service
 	if teamid == "" {
 		return fmt.Errorf("%w: team_id cannot be empty", ErrInvalidTeamId)
 	}
+	if len(teamid) > MaxTeamIdLength {
+		return fmt.Errorf("%w: team_id cannot be longer than %d characters", ErrInvalidTeamId, MaxTeamIdLength)
+	}
 	return nil
 }
 
-func ValidateGuesses(guesses []string) error {
-	if len(guesses) != common.NumTargetWords {
-		return fmt.Errorf("%w: expected %d, got %d", ErrInvalidGuessLength, common.NumTargetWords, len(guesses))
+// Rules are what the guesses of a run are validated against: its number of
+// games and the corpus its words come from. In HardMode every guess must also
+// use everything the earlier hints of its game revealed; History returns the
+// game's earlier guesses and their hints, oldest first. Solved reports whether
+// a game is solved, since only solved games may be sent DummyGuess in
+// HardMode.
+type Rules struct {
+	NumGames int
+	Corpus   *corpus.Corpus
+	HardMode bool
+	History  func(index int) (guesses, hints []string)
+	Solved   func(index int) bool
+}
+
+// ValidateGuesses validates a dense submission, with one guess per game. If
+// the number of guesses is wrong it returns ErrInvalidGuessLength; otherwise
+// it checks every guess and returns all invalid ones together as GuessErrors.
+func ValidateGuesses(guesses []string, rules Rules) error {
+	if len(guesses) != rules.NumGames {
+		return fmt.Errorf("%w: expected %d, got %d", ErrInvalidGuessLength, rules.NumGames, len(guesses))
 	}
 
-	for _, guess := range guesses {
-		if err := validateGuess(guess); err != nil {
-			return err
+	var errs GuessErrors
+	for index, guess := range guesses {
+		if err := rules.validate(index, guess); err != nil {
+			errs = append(errs, &GuessError{Index: index, Guess: guess, Err: err})
 		}
 	}
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
-func validateGuess(guess string) error {
+// ValidateGuessMap validates a sparse submission mapping game index to guess.
+// It must contain at least one guess, and every index must refer to a game.
+// Every invalid index or guess is returned together as GuessErrors.
+func ValidateGuessMap(guesses map[int]string, rules Rules) error {
+	if len(guesses) == 0 {
+		return fmt.Errorf("%w: expected at least 1, got 0", ErrInvalidGuessLength)
+	}
+
+	var errs GuessErrors
+	for _, index := range SortedIndices(guesses) {
+		if index < 0 || index >= rules.NumGames {
+			err := fmt.Errorf("%w: not between 0 and %d", ErrInvalidGameIndex, rules.NumGames-1)
+			errs = append(errs, &GuessError{Index: index, Guess: guesses[index], Err: err})
+			continue
+		}
+		if err := rules.validate(index, guesses[index]); err != nil {
+			errs = append(errs, &GuessError{Index: index, Guess: guesses[index], Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SortedIndices returns the game indices of a sparse submission in ascending order.
+func SortedIndices(guesses map[int]string) []int {
+	indices := make([]int, 0, len(guesses))
+	for index := range guesses {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// validate returns what is wrong with the guess for the game at index, or nil
+// if it may be played.
+func (r Rules) validate(index int, guess string) error {
+	if err := validateGuess(r.Corpus, guess); err != nil {
+		return err
+	}
+	if !r.HardMode {
+		return nil
+	}
+
+	if guess == common.DummyGuess {
+		if !r.Solved(index) {
+			return fmt.Errorf("%w: the dummy guess is only allowed for solved games", ErrHardModeViolation)
+		}
+		return nil
+	}
+	previous, hints := r.History(index)
+	return validateHardModeGuess(guess, previous, hints)
+}
+
+// validateGuess returns the sentinel error describing what is wrong with guess,
+// or nil if it is a word of c or DummyGuess.
+func validateGuess(c *corpus.Corpus, guess string) error {
 	if guess == common.DummyGuess {
 		return nil
 	}
 
-	if len(guess) != common.WordLength {
-		return fmt.Errorf("%w: %s", ErrInvalidWordLength, guess)
+	if utf8.RuneCountInString(guess) != c.WordLength {
+		return ErrInvalidWordLength
 	}
 
-	if !corpus.IsValidWord(guess) {
-		return fmt.Errorf("word not in corpus: %s", guess)
+	if !c.IsValidWord(guess) {
+		return ErrWordNotInCorpus
+	}
+
+	return nil
+}
+
+// validateHardModeGuess checks that guess uses everything revealed by the
+// earlier guesses of its game and their hints: every 'O' letter must stay in
+// place, and every 'O' or '~' letter must appear at least as many times as a
+// single hint revealed it. The error wraps ErrHardModeViolation and names the
+// first rule broken.
+func validateHardModeGuess(guess string, previous, hints []string) error {
+	guessRunes := []rune(guess)
+	have := make(map[rune]int, len(guessRunes))
+	for _, r := range guessRunes {
+		have[r]++
+	}
+
+	for k, hint := range hints {
+		previousRunes := []rune(previous[k])
+		need := make(map[rune]int)
+		for i, mark := range []rune(hint) {
+			if mark == 'O' && (i >= len(guessRunes) || guessRunes[i] != previousRunes[i]) {
+				return fmt.Errorf("%w: letter %d must be %q", ErrHardModeViolation, i+1, string(previousRunes[i]))
+			}
+			if mark == 'O' || mark == '~' {
+				need[previousRunes[i]]++
+			}
+		}
+
+		for i, mark := range []rune(hint) {
+			letter := previousRunes[i]
+			if (mark == 'O' || mark == '~') && have[letter] < need[letter] {
+				if need[letter] == 1 {
+					return fmt.Errorf("%w: guess must contain %q", ErrHardModeViolation, string(letter))
+				}
+				return fmt.Errorf("%w: guess must contain %q %d times", ErrHardModeViolation, string(letter), need[letter])
+			}
+		}
 	}
 
 	return nil
@@ -0,0 +1,141 @@
+package wordle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"wordle-tournament-backend/internal/common"
+	"wordle-tournament-backend/internal/wordle/corpus"
+)
+
+var fullRun = Rules{NumGames: common.NumTargetWords, Corpus: corpus.Default()}
+
+func TestValidateGuessMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		guesses map[int]string
+		wantErr error
+	}{
+		{"valid", map[int]string{0: "crane", 2314: "house"}, nil},
+		{"empty", map[int]string{}, ErrInvalidGuessLength},
+		{"negative index", map[int]string{-1: "crane"}, ErrInvalidGameIndex},
+		{"index out of range", map[int]string{common.NumTargetWords: "crane"}, ErrInvalidGameIndex},
+		{"bad word length", map[int]string{3: "cranes"}, ErrInvalidWordLength},
+		{"not in corpus", map[int]string{3: "xyzzy"}, ErrWordNotInCorpus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGuessMap(tt.guesses, fullRun)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateGuessesReportsEveryInvalidGuess(t *testing.T) {
+	guesses := make([]string, common.NumTargetWords)
+	for i := range guesses {
+		guesses[i] = common.DummyGuess
+	}
+	guesses[7] = "xyzzy"
+	guesses[40] = "cranes"
+	guesses[41] = "crane"
+
+	var guessErrs GuessErrors
+	if err := ValidateGuesses(guesses, fullRun); !errors.As(err, &guessErrs) {
+		t.Fatalf("expected GuessErrors, got %v", err)
+	}
+	if len(guessErrs) != 2 {
+		t.Fatalf("expected 2 invalid guesses, got %v", guessErrs)
+	}
+	if guessErrs[0].Index != 7 || guessErrs[0].Guess != "xyzzy" || !errors.Is(guessErrs[0], ErrWordNotInCorpus) {
+		t.Errorf("unexpected first error %+v", guessErrs[0])
+	}
+	if guessErrs[1].Index != 40 || !errors.Is(guessErrs[1], ErrInvalidWordLength) {
+		t.Errorf("unexpected second error %+v", guessErrs[1])
+	}
+}
+
+func TestValidationFollowsRunSize(t *testing.T) {
+	if err := ValidateGuesses([]string{"crane", "house"}, Rules{NumGames: 2, Corpus: corpus.Default()}); err != nil {
+		t.Errorf("expected a guess per game of a 2-game run to be valid, got %v", err)
+	}
+	if err := ValidateGuesses([]string{"crane"}, Rules{NumGames: 2, Corpus: corpus.Default()}); !errors.Is(err, ErrInvalidGuessLength) {
+		t.Errorf("expected %v, got %v", ErrInvalidGuessLength, err)
+	}
+	if err := ValidateGuessMap(map[int]string{2: "crane"}, Rules{NumGames: 2, Corpus: corpus.Default()}); !errors.Is(err, ErrInvalidGameIndex) {
+		t.Errorf("expected %v, got %v", ErrInvalidGameIndex, err)
+	}
+}
+
+func TestHardMode(t *testing.T) {
+	// The answer is "robot": "roost" was graded "OO~XO".
+	rules := Rules{
+		NumGames: 2,
+		Corpus:   corpus.Default(),
+		HardMode: true,
+		History: func(index int) ([]string, []string) {
+			if index == 0 {
+				return []string{"roost"}, []string{"OO~XO"}
+			}
+			return nil, nil
+		},
+		Solved: func(index int) bool { return index == 1 },
+	}
+
+	tests := []struct {
+		guess   string
+		wantErr string
+	}{
+		{"robot", ""},
+		{"rotor", `letter 5 must be "t"`},
+		{"rowdy", `letter 5 must be "t"`},
+		{"rocket", "wrong number of letters"},
+		{"roust", `guess must contain "o" 2 times`},
+		{common.DummyGuess, "only allowed for solved games"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.guess, func(t *testing.T) {
+			err := ValidateGuessMap(map[int]string{0: tt.guess, 1: "crane"}, rules)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var guessErrs GuessErrors
+			if !errors.As(err, &guessErrs) || len(guessErrs) != 1 || guessErrs[0].Index != 0 {
+				t.Fatalf("expected one invalid guess for game 0, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected %q in %q", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := ValidateGuessMap(map[int]string{0: "robot", 1: common.DummyGuess}, rules); err != nil {
+		t.Errorf("expected DummyGuess to be allowed for a solved game, got %v", err)
+	}
+	if err := ValidateGuessMap(map[int]string{0: "rotor"}, Rules{NumGames: 2, Corpus: corpus.Default(), History: rules.History}); err != nil {
+		t.Errorf("hard mode rules should only apply in hard mode, got %v", err)
+	}
+}
+
+func TestHardModeRequiresPresentLetters(t *testing.T) {
+	err := validateHardModeGuess("slate", []string{"crane"}, []string{"X~XX~"})
+	if !errors.Is(err, ErrHardModeViolation) || !strings.Contains(err.Error(), `guess must contain "r"`) {
+		t.Errorf("expected a %v naming the revealed 'r', got %v", ErrHardModeViolation, err)
+	}
+
+	if err := validateHardModeGuess("rebus", []string{"crane"}, []string{"X~XX~"}); err != nil {
+		t.Errorf("expected a guess using every revealed letter to be valid, got %v", err)
+	}
+}